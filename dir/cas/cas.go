@@ -0,0 +1,188 @@
+// Package cas a content-addressed file store, identical content is stored once
+package cas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrNotFound      = errors.New("No such object in store")
+	ErrInvalidDigest = errors.New("Invalid digest")
+)
+
+// Store keeps objects under root/objects/<first two hex digits>/<rest of the sha256 digest>
+type Store struct {
+	root string
+}
+
+// New open the store at root, creating it if needed
+func New(root string) (*Store, error) {
+	for _, d := range []string{"objects", "tmp"} {
+		err := os.MkdirAll(filepath.Join(root, d), 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Store{root: root}, nil
+}
+
+// Put store the content of r and return its sha256 hex digest.
+// Storing content already in the store is a no-op.
+func (s *Store) Put(r io.Reader) (string, error) {
+	f, err := ioutil.TempFile(filepath.Join(s.root, "tmp"), "put")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return "", err
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	p := s.path(digest)
+
+	if _, err := os.Stat(p); err == nil {
+		return digest, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return "", err
+	}
+	// objects are immutable, they may be hard linked into many places
+	err = os.Chmod(tmp, 0444)
+	if err != nil {
+		return "", err
+	}
+	return digest, os.Rename(tmp, p)
+}
+
+// Get return the path of the object with digest
+func (s *Store) Get(digest string) (string, error) {
+	if !valid(digest) {
+		return "", ErrInvalidDigest
+	}
+	p := s.path(digest)
+	_, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	return p, err
+}
+
+// Has whether the object with digest is in the store
+func (s *Store) Has(digest string) bool {
+	_, err := s.Get(digest)
+	return err == nil
+}
+
+// Link make the object with digest available at dest, as a hard link when
+// possible and as a copy otherwise, eg: across file systems. An existing dest
+// is replaced atomically. A hard link shares its content with the store and
+// every other link, objects are read-only so that modifying dest in place
+// fails, callers must replace dest instead of making it writable.
+func (s *Store) Link(digest, dest string) error {
+	p, err := s.Get(digest)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(dest)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// stage next to dest, so dest is never missing and rename stays on one file system
+	out, err := ioutil.TempFile(dir, "."+filepath.Base(dest)+".link")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	defer os.Remove(tmp)
+
+	out.Close()
+	os.Remove(tmp)
+	if os.Link(p, tmp) == nil {
+		return os.Rename(tmp, dest)
+	}
+
+	in, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err = os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err1 := out.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// GC remove every object whose digest is not in referenced, it returns the number of removed objects
+func (s *Store) GC(referenced []string) (int, error) {
+	keep := make(map[string]struct{}, len(referenced))
+	for _, v := range referenced {
+		keep[v] = struct{}{}
+	}
+
+	var n int
+	objects := filepath.Join(s.root, "objects")
+	err := filepath.Walk(objects, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		digest := filepath.Base(filepath.Dir(p)) + info.Name()
+		if _, ok := keep[digest]; ok {
+			return nil
+		}
+		err = os.Remove(p)
+		if err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+func (s *Store) path(digest string) string {
+	return filepath.Join(s.root, "objects", digest[:2], digest[2:])
+}
+
+// valid whether digest is a lowercase sha256 hex digest, which also keeps it from escaping the store
+func valid(digest string) bool {
+	if len(digest) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range digest {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package cas
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	d := t.TempDir()
+	s, err := New(filepath.Join(d, "store"))
+	if err != nil {
+		t.Fatalf("[cas]: New test failed with %s", err)
+	}
+
+	digest, err := s.Put(strings.NewReader("hello"))
+	digest1, err1 := s.Put(strings.NewReader("hello"))
+	if err != nil || err1 != nil || digest != digest1 {
+		t.Errorf("[cas]: Put test failed, expecting the same digest, got %s %s, err %v %v", digest, digest1, err, err1)
+	}
+
+	dest := filepath.Join(d, "out", "hello.txt")
+	if err := s.Link(digest, dest); err != nil {
+		t.Errorf("[cas]: Link test failed with %s", err)
+	}
+	if b, _ := ioutil.ReadFile(dest); string(b) != "hello" {
+		t.Errorf("[cas]: Link test failed, expecting hello, got %s", b)
+	}
+
+	other, _ := s.Put(strings.NewReader("world"))
+	if err := s.Link(other, dest); err != nil {
+		t.Errorf("[cas]: Link test failed with %s", err)
+	}
+	entries, _ := ioutil.ReadDir(filepath.Dir(dest))
+	if b, _ := ioutil.ReadFile(dest); string(b) != "world" || len(entries) != 1 {
+		t.Errorf("[cas]: Link test failed, expecting dest replaced by world and no staged file left, got %s and %d entries", b, len(entries))
+	}
+	if n, err := s.GC([]string{digest}); n != 1 || err != nil || s.Has(other) || !s.Has(digest) {
+		t.Errorf("[cas]: GC test failed, expecting 1 removed object, got %d, err %v", n, err)
+	}
+}
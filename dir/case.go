@@ -0,0 +1,116 @@
+package dir
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"unicode"
+)
+
+// Collision the entries of a directory whose names only differ by case,
+// they would overwrite each other on a case-insensitive file system.
+type Collision struct {
+	Dir   string
+	Names []string
+}
+
+// FindCaseInsensitive find the paths under root matching the relative path name
+// regardless of case, eg: "docs/readme.md" finds "Docs/README.md".
+// Several paths are returned if the tree has case collisions.
+func FindCaseInsensitive(root, name string) ([]string, error) {
+	candidates := []string{root}
+	for _, c := range strings.Split(filepath.Clean(name), string(filepath.Separator)) {
+		if len(c) == 0 || c == "." {
+			continue
+		}
+		var next []string
+		for _, d := range candidates {
+			entries, err := ioutil.ReadDir(d)
+			if err != nil {
+				if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+					continue
+				}
+				return nil, err
+			}
+			for _, e := range entries {
+				if strings.EqualFold(e.Name(), c) {
+					next = append(next, filepath.Join(d, e.Name()))
+				}
+			}
+		}
+		candidates = next
+		if len(candidates) == 0 {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Strings(candidates)
+	return candidates, nil
+}
+
+// DetectCaseCollisions find the directories under root, root included, holding
+// entries whose names only differ by case.
+func DetectCaseCollisions(root string) ([]Collision, error) {
+	var collisions []Collision
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		names, err := readDirNames(p)
+		if err != nil {
+			return err
+		}
+		m := make(map[string][]string)
+		var keys []string
+		for _, n := range names {
+			k := foldCase(n)
+			if _, ok := m[k]; !ok {
+				keys = append(keys, k)
+			}
+			m[k] = append(m[k], n)
+		}
+		for _, k := range keys {
+			if len(m[k]) > 1 {
+				collisions = append(collisions, Collision{Dir: p, Names: m[k]})
+			}
+		}
+		return nil
+	})
+
+	return collisions, err
+}
+
+// foldCase a key equal for all the names strings.EqualFold considers equal
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		// SimpleFold cycles through the equivalent runes, use the smallest one
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	sort.Strings(names)
+	return names, err
+}
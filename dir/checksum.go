@@ -0,0 +1,58 @@
+package dir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Checksum a deterministic SHA-256 digest of the tree under root: of the
+// relative paths, types and permission bits of all its entries, the contents
+// of its regular files and the targets of its symlinks. Two trees have the
+// same checksum if they are identical, wherever they are, whatever their
+// timestamps and owners, eg: to verify build outputs are reproducible.
+func Checksum(root string) (string, error) {
+	h := sha256.New()
+	err := Walk(root, func(p string, info os.FileInfo) error {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		sum, err := entryDigest(p, info)
+		if err != nil {
+			return err
+		}
+		// the path is quoted so that names with newlines can't forge entries
+		fmt.Fprintf(h, "%s %q %s\n", info.Mode().String(), filepath.ToSlash(rel), sum)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// entryDigest the hash of the content of a regular file, the target of a
+// symlink, nothing for the other types
+func entryDigest(p string, info os.FileInfo) (string, error) {
+	switch {
+	case info.Mode().IsRegular():
+		f, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(p)
+		return fmt.Sprintf("%q", filepath.ToSlash(link)), err
+	}
+	return "-", nil
+}
@@ -0,0 +1,244 @@
+package dir
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format a compression format of CompressFiles
+type Format int
+
+const (
+	// Gzip compress to path.gz
+	Gzip Format = iota
+	// Zlib compress to path.zz
+	Zlib
+)
+
+// Ext the file name extension of the format
+func (f Format) Ext() string {
+	if f == Zlib {
+		return ".zz"
+	}
+	return ".gz"
+}
+
+func (f Format) writer(w io.Writer) (io.WriteCloser, error) {
+	switch f {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zlib:
+		return zlib.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown compression format %d", f)
+}
+
+type compressOptions struct {
+	workers   int
+	olderThan time.Duration
+	progress  func(path string, done, total int)
+}
+
+// CompressOption configures CompressFiles
+type CompressOption func(*compressOptions)
+
+// WithCompressWorkers compress n files at once instead of one per CPU
+func WithCompressWorkers(n int) CompressOption {
+	return func(o *compressOptions) {
+		o.workers = n
+	}
+}
+
+// WithOlderThan only compress the files last modified more than d ago
+func WithOlderThan(d time.Duration) CompressOption {
+	return func(o *compressOptions) {
+		o.olderThan = d
+	}
+}
+
+// WithProgress call fn after every file compressed, with the number of files
+// done so far and the total. It is called from one goroutine at a time.
+func WithProgress(fn func(path string, done, total int)) CompressOption {
+	return func(o *compressOptions) {
+		o.progress = fn
+	}
+}
+
+// CompressFiles compress the regular files under root matching pattern, eg:
+// "*.log" with WithOlderThan(7*24*time.Hour) to compress week-old logs. A
+// pattern without "/" is matched against base names, otherwise against paths
+// relative to root, see GlobMatcher. Compressed files keep the mode and times
+// of the originals, which are removed unless keepOriginal. Files already
+// having the extension of the format are skipped, existing compressed files
+// are never replaced and fail with os.ErrExist. The files are all tried, the
+// ones that failed are listed in the error.
+func CompressFiles(root string, pattern string, format Format, keepOriginal bool, opts ...CompressOption) error {
+	o := compressOptions{workers: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workers < 1 {
+		o.workers = 1
+	}
+	if _, err := format.writer(ioutil.Discard); err != nil {
+		return err
+	}
+
+	m, err := NewGlobMatcher(pattern)
+	if err != nil {
+		return err
+	}
+	base := !strings.Contains(pattern, "/")
+
+	var files []string
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || strings.HasSuffix(p, format.Ext()) {
+			return nil
+		}
+		if o.olderThan > 0 && time.Since(info.ModTime()) < o.olderThan {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if base {
+			rel = filepath.Base(rel)
+		}
+		if m.Match(rel) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		done int
+		errs []string
+	)
+	jobs := make(chan string)
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				err := compressFile(p, format, keepOriginal)
+				mu.Lock()
+				done++
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", p, err))
+				}
+				if o.progress != nil {
+					o.progress(p, done, len(files))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range files {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("failed to compress %d files: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// renameNoReplace rename old to new unless new exists. A hard link fails
+// atomically on an existing new, rename is the fallback where links aren't
+// supported.
+func renameNoReplace(old, new string) error {
+	err := os.Link(old, new)
+	if err == nil {
+		return os.Remove(old)
+	}
+	if os.IsExist(err) {
+		return &os.LinkError{Op: "rename", Old: old, New: new, Err: os.ErrExist}
+	}
+	if _, err := os.Lstat(new); err == nil {
+		return &os.LinkError{Op: "rename", Old: old, New: new, Err: os.ErrExist}
+	}
+	return os.Rename(old, new)
+}
+
+// compressFile compress p to p plus the extension of format, through a temporary file
+func compressFile(p string, format Format, keepOriginal bool) error {
+	start := time.Now()
+	target := p + format.Ext()
+	if _, err := os.Lstat(target); err == nil {
+		err = &os.PathError{Op: "compress", Path: target, Err: os.ErrExist}
+		observe("compress", target, start, 0, err)
+		return err
+	}
+
+	in, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(p), "."+filepath.Base(target))
+	if err != nil {
+		observe("compress", target, start, 0, err)
+		return err
+	}
+	tmp := f.Name()
+
+	zw, err := format.writer(f)
+	var n int64
+	if err == nil {
+		n, err = io.Copy(zw, in)
+		if err1 := zw.Close(); err == nil {
+			err = err1
+		}
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Chmod(tmp, info.Mode().Perm())
+	}
+	if err == nil {
+		err = copyTimes(p, tmp, info)
+	}
+	if err == nil {
+		err = renameNoReplace(tmp, target)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	observe("compress", target, start, n, err)
+	if err != nil || keepOriginal {
+		return err
+	}
+
+	start = time.Now()
+	err = os.Remove(p)
+	observe("remove", p, start, 0, err)
+	return err
+}
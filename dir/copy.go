@@ -0,0 +1,277 @@
+package dir
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CopyPolicy decides what CopyDir does with the files already in the destination
+type CopyPolicy int
+
+const (
+	// CopyError fail with an error wrapping os.ErrExist
+	CopyError CopyPolicy = iota
+	// CopySkip keep what is there
+	CopySkip
+	// CopyOverwrite replace what is there
+	CopyOverwrite
+	// CopyMerge replace what is there only if the source is newer
+	CopyMerge
+)
+
+type copyOptions struct {
+	policy  CopyPolicy
+	matcher PathMatcher
+}
+
+// CopyOption configures CopyDir
+type CopyOption func(*copyOptions)
+
+// WithCopyPolicy handle existing files with p instead of CopyError
+func WithCopyPolicy(p CopyPolicy) CopyOption {
+	return func(o *copyOptions) {
+		o.policy = p
+	}
+}
+
+// WithCopyMatcher only copy the paths, relative to src, m matches
+func WithCopyMatcher(m PathMatcher) CopyOption {
+	return func(o *copyOptions) {
+		o.matcher = m
+	}
+}
+
+// CopyDir copy the tree under src to dst recursively, preserving the
+// permissions and timestamps of files and directories, and copying symlinks
+// as symlinks. Directories existing in dst are merged with the ones of src,
+// the policy decides for the other paths. Files and symlinks are written to a
+// temporary name renamed into place, so readers never see a partial file and
+// a replaced path only goes once its replacement is complete. Special files,
+// like devices and sockets, are skipped.
+func CopyDir(src, dst string, opts ...CopyOption) error {
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// the walk would descend into the copy while writing it, endlessly
+	rsrc, err := resolveExisting(src)
+	if err != nil {
+		return err
+	}
+	rdst, err := resolveExisting(dst)
+	if err != nil {
+		return err
+	}
+	if within(rsrc, rdst) {
+		return fmt.Errorf("copy %s to %s: the destination is inside the source", src, dst)
+	}
+
+	// the modes and times of directories are set after their content is written
+	type dirInfo struct {
+		source, path string
+		info         os.FileInfo
+	}
+	var dirs []dirInfo
+
+	err = filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if o.matcher != nil && rel != "." && !o.matcher.Match(rel) {
+			if info.IsDir() && !descend(o.matcher, rel) {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() {
+				return nil
+			}
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			if err := copyMkdir(target); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirInfo{p, target, info})
+			return nil
+		}
+
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		ok, err := o.replace(target, info)
+		if err != nil || !ok {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return copySymlink(p, target)
+		}
+		return copyFile(p, target, info)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].info.Mode().Perm()|dirs[i].info.Mode()&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+			return err
+		}
+		if err := copyTimes(dirs[i].source, dirs[i].path, dirs[i].info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveExisting p made absolute with the symlinks of its longest existing
+// prefix resolved, its leaf included, eg: dst/a/b with dst a symlink to src
+// resolves to src/a/b
+func resolveExisting(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	var rest []string
+	for {
+		if r, err := filepath.EvalSymlinks(abs); err == nil {
+			for i := len(rest) - 1; i >= 0; i-- {
+				r = filepath.Join(r, rest[i])
+			}
+			return r, nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return filepath.Join(append([]string{abs}, rest...)...), nil
+		}
+		rest = append(rest, filepath.Base(abs))
+		abs = parent
+	}
+}
+
+// replace whether target should be (re)written for the source described by info
+func (o copyOptions) replace(target string, info os.FileInfo) (bool, error) {
+	tinfo, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch o.policy {
+	case CopySkip:
+		return false, nil
+	case CopyMerge:
+		if !info.ModTime().After(tinfo.ModTime()) {
+			return false, nil
+		}
+	case CopyError:
+		return false, fmt.Errorf("copy to %s: %w", target, os.ErrExist)
+	}
+	return true, nil
+}
+
+// renameOver rename tmp to target. A directory in the way is removed only
+// then, once its replacement is complete, anything else is renamed over.
+func renameOver(tmp, target string) error {
+	if info, err := os.Lstat(target); err == nil && info.IsDir() {
+		start := time.Now()
+		err = os.RemoveAll(target)
+		observe("remove", target, start, 0, err)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, target)
+}
+
+func copyMkdir(target string) error {
+	info, err := os.Stat(target)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("copy to %s: %w, not a directory", target, os.ErrExist)
+		}
+		return nil
+	}
+	start := time.Now()
+	// writable until the mode of the source is applied
+	err = os.MkdirAll(target, 0700)
+	observe("mkdir", target, start, 0, err)
+	return err
+}
+
+func copySymlink(source, target string) error {
+	link, err := os.Readlink(source)
+	if err != nil {
+		return err
+	}
+	// symlink next to it and rename over it, so the name never disappears
+	tmp, err := UniquePath(filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".tmp"))
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = os.Symlink(link, tmp)
+	if err == nil {
+		if err = renameOver(tmp, target); err != nil {
+			os.Remove(tmp)
+		}
+	}
+	observe("symlink", target, start, 0, err)
+	return err
+}
+
+func copyFile(source, target string, info os.FileInfo) error {
+	start := time.Now()
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	f, err := ioutil.TempFile(filepath.Dir(target), "."+filepath.Base(target))
+	if err != nil {
+		observe("copy", target, start, 0, err)
+		return err
+	}
+	tmp := f.Name()
+
+	n, err := io.Copy(f, in)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Chmod(tmp, info.Mode().Perm()|info.Mode()&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	}
+	if err == nil {
+		err = copyTimes(source, tmp, info)
+	}
+	if err == nil {
+		err = renameOver(tmp, target)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	observe("copy", target, start, n, err)
+	return err
+}
+
+// copyTimes apply the access and modification times of source, described by info, to target
+func copyTimes(source, target string, info os.FileInfo) error {
+	atime := info.ModTime()
+	if t, err := GetTimes(source); err == nil {
+		atime = t.Access
+	}
+	return SetTimes(target, atime, info.ModTime())
+}
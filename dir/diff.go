@@ -0,0 +1,113 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DiffKind how an entry differs between two trees, Modified and
+// ModeChanged can be combined
+type DiffKind int
+
+const (
+	// Added the entry is only in the second tree
+	Added DiffKind = 1 << iota
+	// Removed the entry is only in the first tree
+	Removed
+	// Modified the content, symlink target or type of the entry differs
+	Modified
+	// ModeChanged the permission bits of the entry differ
+	ModeChanged
+)
+
+func (k DiffKind) String() string {
+	var s []string
+	for i, v := range []string{"added", "removed", "modified", "mode changed"} {
+		if k&(1<<uint(i)) != 0 {
+			s = append(s, v)
+		}
+	}
+	return strings.Join(s, "|")
+}
+
+// DiffEntry an entry differing between two trees, Path is relative to their roots
+type DiffEntry struct {
+	Path string
+	Kind DiffKind
+}
+
+// Diff compare the trees under a and b, like "diff -r" does, and return their
+// differing entries sorted by path. Regular files are compared by their
+// SHA-256 when they have the same size. Timestamps and owners are ignored.
+func Diff(a, b string) ([]DiffEntry, error) {
+	ta, err := treeInfos(a)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := treeInfos(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []DiffEntry
+	for rel, ia := range ta {
+		ib, ok := tb[rel]
+		if !ok {
+			res = append(res, DiffEntry{rel, Removed})
+			continue
+		}
+
+		var kind DiffKind
+		if ia.Mode().Type() != ib.Mode().Type() {
+			kind |= Modified
+		} else if ia.Mode().IsRegular() && ia.Size() != ib.Size() {
+			kind |= Modified
+		} else {
+			da, err := entryDigest(filepath.Join(a, rel), ia)
+			if err != nil {
+				return nil, err
+			}
+			db, err := entryDigest(filepath.Join(b, rel), ib)
+			if err != nil {
+				return nil, err
+			}
+			if da != db {
+				kind |= Modified
+			}
+		}
+		// symlinks have no meaningful permissions
+		if ia.Mode()&os.ModeSymlink == 0 && ia.Mode().Perm() != ib.Mode().Perm() {
+			kind |= ModeChanged
+		}
+		if kind != 0 {
+			res = append(res, DiffEntry{rel, kind})
+		}
+	}
+	for rel := range tb {
+		if _, ok := ta[rel]; !ok {
+			res = append(res, DiffEntry{rel, Added})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res, nil
+}
+
+// treeInfos the infos of the entries under root by relative path, root excluded
+func treeInfos(root string) (map[string]os.FileInfo, error) {
+	m := make(map[string]os.FileInfo)
+	err := Walk(root, func(p string, info os.FileInfo) error {
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		m[rel] = info
+		return nil
+	})
+	return m, err
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/marguerite/go-stdlib/extglob"
 	"github.com/marguerite/go-stdlib/internal"
@@ -39,9 +40,25 @@ func FollowSymlink(path string) (link string, err error) {
 // recursive: whether to recursively list the second level file list
 // kind: if set, will only list the direcories
 func Ls(directory string, symlink, recursive bool, kind ...string) (files []string, err error) {
+	entries, err := LsInfo(directory, symlink, recursive, kind...)
+	for _, e := range entries {
+		files = append(files, e.Path)
+	}
+	return files, err
+}
+
+// Entry a path listed by LsInfo with its file info
+type Entry struct {
+	Path string
+	Info os.FileInfo
+}
+
+// LsInfo like Ls, but return the file info read while listing along with
+// every path, so that callers don't have to stat them again
+func LsInfo(directory string, symlink, recursive bool, kind ...string) (entries []Entry, err error) {
 	directories, err := extglob.Expand(internal.Str2bytes(directory))
 	if err != nil {
-		return files, err
+		return entries, err
 	}
 
 	for _, v := range directories {
@@ -59,12 +76,12 @@ func Ls(directory string, symlink, recursive bool, kind ...string) (files []stri
 			link, err := FollowSymlink(v)
 			f.Close()
 			if err != nil {
-				return files, err
+				return entries, err
 			}
 			f, err = os.Open(link)
 			if err != nil {
 				f.Close()
-				return files, err
+				return entries, err
 			}
 		}
 
@@ -72,27 +89,25 @@ func Ls(directory string, symlink, recursive bool, kind ...string) (files []stri
 			items, err := f.Readdir(-1)
 			if err != nil {
 				f.Close()
-				return files, err
+				return entries, err
 			}
 
 			for _, j := range items {
 				path := filepath.Join(v, j.Name())
 
 				if j.IsDir() {
-					files = append(files, path)
+					entries = append(entries, Entry{path, j})
 				} else if len(kind) == 0 {
-					files = append(files, path)
+					entries = append(entries, Entry{path, j})
 				}
 
 				if recursive && j.IsDir() {
-					subfiles, err := Ls(path, symlink, recursive, kind...)
+					subentries, err := LsInfo(path, symlink, recursive, kind...)
 					if err != nil {
 						f.Close()
-						return files, err
-					}
-					for _, sub := range subfiles {
-						files = append(files, sub)
+						return entries, err
 					}
+					entries = append(entries, subentries...)
 				}
 			}
 			f.Close()
@@ -100,35 +115,90 @@ func Ls(directory string, symlink, recursive bool, kind ...string) (files []stri
 		}
 
 		if len(kind) == 0 {
-			files = append(files, v)
+			entries = append(entries, Entry{v, i})
 		}
 
 		f.Close()
 	}
 
-	sort.Strings(files)
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Path < entries[b].Path })
 
-	return files, nil
+	return entries, nil
 }
 
-// MkdirP create directories for path
+// MkdirP create directories for path, like "mkdir -p" it succeeds if path is already a directory
 func MkdirP(path string) error {
-	_, err := os.Stat(path)
-	if err == nil {
-		return os.ErrExist
+	return MkdirPMode(path, os.ModePerm)
+}
+
+type mkdirOptions struct {
+	chown    bool
+	uid, gid int
+}
+
+// MkdirOption configures MkdirPMode
+type MkdirOption func(*mkdirOptions)
+
+// WithMkdirOwner chown the directories MkdirPMode creates to uid and gid,
+// the existing parents are left alone
+func WithMkdirOwner(uid, gid int) MkdirOption {
+	return func(o *mkdirOptions) {
+		o.chown = true
+		o.uid = uid
+		o.gid = gid
+	}
+}
+
+// MkdirPMode create directories for path with mode, before umask, succeeding
+// if path is already a directory
+func MkdirPMode(path string, mode os.FileMode, opts ...MkdirOption) error {
+	var o mkdirOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
 	}
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(path, os.ModePerm)
+
+	// the missing components, deepest first
+	var missing []string
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			break
+		}
+		missing = append(missing, p)
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+
+	start := time.Now()
+	err = os.MkdirAll(path, mode)
+	observe("mkdir", path, start, 0, err)
+	if err != nil || !o.chown {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		start := time.Now()
+		err := os.Lchown(missing[i], o.uid, o.gid)
+		observe("chown", missing[i], start, 0, err)
 		if err != nil {
 			return err
 		}
-		return nil
 	}
-	return err
+	return nil
 }
 
 // Glob glob actual files via the pattern, pattern can be *regexp.Regexp or string
-// when *regexp.Regexp is used, base is a must.
+// when *regexp.Regexp is used, base is a must. A string pattern with a "**"
+// component matches any number of directories, like bash's globstar, eg:
+// "src/**/*.go"; such patterns use the syntax of GlobMatcher. The exclusion is
+// of the same type as the pattern, or an *Ignore applied to the paths relative to base.
 func Glob(patt interface{}, opts ...interface{}) ([]string, error) {
 	if len(opts) > 2 {
 		return []string{}, fmt.Errorf("opts just have two values: base and exclusion")
@@ -161,19 +231,19 @@ func Glob(patt interface{}, opts ...interface{}) ([]string, error) {
 				files = append(files, v)
 			}
 		}
-		return files, nil
+		return filterIgnored(files, base, opts)
 	case string:
 		// string match
 		if len(base) > 0 {
 			val = filepath.Join(base, val)
 		}
-		matches, err := extglob.Expand(internal.Str2bytes(val))
+		matches, err := expandString(val)
 		if err != nil {
 			return matches, err
 		}
 		if len(opts) > 1 {
 			if val1, ok := opts[1].(string); ok {
-				m, err := extglob.Expand(internal.Str2bytes(filepath.Join(base, val1)))
+				m, err := expandString(filepath.Join(base, val1))
 				if err != nil {
 					return matches, err
 				}
@@ -183,7 +253,68 @@ func Glob(patt interface{}, opts ...interface{}) ([]string, error) {
 				}
 			}
 		}
-		return matches, nil
+		return filterIgnored(matches, base, opts)
 	}
 	return []string{}, nil
 }
+
+// expandString expand a string pattern, with globstar for the ones with a "**" component
+func expandString(patt string) ([]string, error) {
+	for _, c := range splitPath(filepath.Clean(patt)) {
+		if c == "**" {
+			return globstar(patt)
+		}
+	}
+	return extglob.Expand(internal.Str2bytes(patt))
+}
+
+// globstar walk the tree under the static root of patt, only descending into
+// the directories that can hold matches. Unreadable directories are skipped.
+func globstar(patt string) ([]string, error) {
+	m, err := NewGlobMatcher(patt)
+	if err != nil {
+		return []string{}, err
+	}
+	root := m.Root()
+
+	matches := []string{}
+	err = Walk(root, func(p string, info os.FileInfo) error {
+		if p == "." {
+			return nil
+		}
+		if m.Match(p) {
+			matches = append(matches, p)
+		}
+		if info.IsDir() && p != root && !m.Descend(p) {
+			return SkipDir
+		}
+		return nil
+	}, WithErrorHandler(func(string, error) error { return nil }))
+	return matches, err
+}
+
+// filterIgnored remove the paths an *Ignore exclusion in opts ignores
+func filterIgnored(paths []string, base string, opts []interface{}) ([]string, error) {
+	if len(opts) < 2 {
+		return paths, nil
+	}
+	ign, ok := opts[1].(*Ignore)
+	if !ok {
+		return paths, nil
+	}
+	m := ign.Matcher(base)
+	files := make([]string, 0, len(paths))
+	for _, v := range paths {
+		rel := v
+		if len(base) > 0 {
+			var err error
+			if rel, err = filepath.Rel(base, v); err != nil {
+				return files, err
+			}
+		}
+		if m.Match(rel) {
+			files = append(files, v)
+		}
+	}
+	return files, nil
+}
@@ -1,18 +1,30 @@
 package dir
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/marguerite/go-stdlib/slice"
 )
 
 func TestLs(t *testing.T) {
 	cwd, _ := os.Getwd()
-	correct := []string{filepath.Join(cwd, "dir.go"), filepath.Join(cwd, "dir_test.go")}
+	var correct []string
+	filepath.Walk(cwd, func(p string, info os.FileInfo, err error) error {
+		if p != cwd {
+			correct = append(correct, p)
+		}
+		return nil
+	})
 	if files, err := Ls(cwd, true, true); !reflect.DeepEqual(files, correct) || err != nil {
 		t.Errorf("[dir]Ls test failed, expecting %s, got %s, err %v", correct, files, err)
 	}
@@ -70,3 +82,845 @@ func TestGlobRegexWithExclusion(t *testing.T) {
 		t.Errorf("[dir]: Glob test failed, expecting %s, got empty", correct)
 	}
 }
+
+func TestUniquePath(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "file.tar.gz")
+	if u, err := UniquePath(p); u != p || err != nil {
+		t.Errorf("[dir]: UniquePath test failed, expecting %s, got %s, err %v", p, u, err)
+	}
+	os.WriteFile(p, nil, 0644)
+	os.WriteFile(filepath.Join(d, "file (1).tar.gz"), nil, 0644)
+	correct := filepath.Join(d, "file (2).tar.gz")
+	if u, err := UniquePath(p); u != correct || err != nil {
+		t.Errorf("[dir]: UniquePath test failed, expecting %s, got %s, err %v", correct, u, err)
+	}
+
+	for name, correct := range map[string]string{".bashrc": ".bashrc (1)", ".config.json": ".config (1).json"} {
+		p := filepath.Join(d, name)
+		os.WriteFile(p, nil, 0644)
+		if u, err := UniquePath(p); u != filepath.Join(d, correct) || err != nil {
+			t.Errorf("[dir]: UniquePath test failed, expecting %s, got %s, err %v", correct, u, err)
+		}
+	}
+}
+
+func TestWatchGlob(t *testing.T) {
+	d := t.TempDir()
+	w, err := WatchGlob(filepath.Join(d, "**", "*.log"), Create)
+	if err == ErrWatchUnsupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("[dir]: WatchGlob test failed with %s", err)
+	}
+	defer w.Close()
+
+	os.WriteFile(filepath.Join(d, "a.txt"), nil, 0644)
+	os.MkdirAll(filepath.Join(d, "sub"), 0755)
+	os.WriteFile(filepath.Join(d, "sub", "b.log"), nil, 0644)
+
+	correct := filepath.Join(d, "sub", "b.log")
+	select {
+	case e := <-w.Events():
+		if e.Path != correct || e.Op != Create {
+			t.Errorf("[dir]: WatchGlob test failed, expecting CREATE %s, got %s %s", correct, e.Op, e.Path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("[dir]: WatchGlob test failed, expecting CREATE %s, got nothing", correct)
+	}
+}
+
+func TestCaseCollisions(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "Docs"), 0755)
+	os.WriteFile(filepath.Join(d, "Docs", "README.md"), nil, 0644)
+	os.WriteFile(filepath.Join(d, "Docs", "readme.md"), nil, 0644)
+
+	correct := []string{filepath.Join(d, "Docs", "README.md"), filepath.Join(d, "Docs", "readme.md")}
+	if found, err := FindCaseInsensitive(d, "docs/Readme.MD"); !reflect.DeepEqual(found, correct) || err != nil {
+		t.Errorf("[dir]: FindCaseInsensitive test failed, expecting %s, got %s, err %v", correct, found, err)
+	}
+
+	collisions, err := DetectCaseCollisions(d)
+	if len(collisions) != 1 || collisions[0].Dir != filepath.Join(d, "Docs") || err != nil {
+		t.Errorf("[dir]: DetectCaseCollisions test failed, expecting one collision in Docs, got %v, err %v", collisions, err)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	d := t.TempDir()
+	f, err := Probe(d)
+	if err != nil {
+		t.Fatalf("[dir]: Probe test failed with %s", err)
+	}
+	if entries, _ := os.ReadDir(d); len(entries) != 0 {
+		t.Errorf("[dir]: Probe test failed, expecting no leftover, got %d entries", len(entries))
+	}
+	// checked independently, the temporary directory may be on any file system
+	os.WriteFile(filepath.Join(d, "case"), nil, 0644)
+	_, err = os.Lstat(filepath.Join(d, "CASE"))
+	if sensitive := os.IsNotExist(err); f.CaseSensitive != sensitive {
+		t.Errorf("[dir]: Probe test failed, expecting CaseSensitive %v, got %+v", sensitive, f)
+	}
+}
+
+func TestCopyPermissions(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(src, "run.sh"), nil, 0755)
+	os.WriteFile(filepath.Join(src, "only-in-src"), nil, 0600)
+	os.WriteFile(filepath.Join(dst, "run.sh"), nil, 0644)
+
+	if err := CopyPermissions(src, dst); err != nil {
+		t.Errorf("[dir]: CopyPermissions test failed with %s", err)
+	}
+	if info, _ := os.Stat(filepath.Join(dst, "run.sh")); info.Mode().Perm() != 0755 {
+		t.Errorf("[dir]: CopyPermissions test failed, expecting mode 0755, got %o", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "only-in-src")); !os.IsNotExist(err) {
+		t.Errorf("[dir]: CopyPermissions test failed, expecting only-in-src to be skipped, got err %v", err)
+	}
+
+	// sub is a directory in src but a symlink leading out of dst
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret"), nil, 0600)
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "secret"), nil, 0644)
+	os.Symlink(outside, filepath.Join(dst, "sub"))
+	if err := CopyPermissions(src, dst); err != nil {
+		t.Errorf("[dir]: CopyPermissions test failed with %s", err)
+	}
+	if info, _ := os.Stat(filepath.Join(outside, "secret")); info.Mode().Perm() != 0600 {
+		t.Errorf("[dir]: CopyPermissions test failed, expecting the symlinked directory skipped, got mode %o", info.Mode().Perm())
+	}
+}
+
+func TestBuildSymlinkFarm(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "a"), 0755)
+	os.MkdirAll(filepath.Join(d, "b"), 0755)
+	os.WriteFile(filepath.Join(d, "a", "plugin.so"), nil, 0644)
+	os.WriteFile(filepath.Join(d, "b", "plugin.so"), nil, 0644)
+	targets := []string{filepath.Join(d, "a", "plugin.so"), filepath.Join(d, "b", "plugin.so")}
+	farm := filepath.Join(d, "farm")
+
+	if err := BuildSymlinkFarm(targets, farm); !errors.Is(err, ErrCollision) {
+		t.Errorf("[dir]: BuildSymlinkFarm test failed, expecting ErrCollision, got %v", err)
+	}
+
+	farm = filepath.Join(d, "farm1")
+	err := BuildSymlinkFarm(targets, farm, WithCollisionPolicy(CollisionRename), WithRelativeLinks())
+	link, _ := os.Readlink(filepath.Join(farm, "plugin (1).so"))
+	correct := filepath.Join("..", "b", "plugin.so")
+	if link != correct || err != nil {
+		t.Errorf("[dir]: BuildSymlinkFarm test failed, expecting %s, got %s, err %v", correct, link, err)
+	}
+
+	err = BuildSymlinkFarm(targets, farm, WithCollisionPolicy(CollisionRename), WithRelativeLinks())
+	names, _ := readDirNames(farm)
+	if correct := []string{"plugin (1).so", "plugin.so"}; !reflect.DeepEqual(names, correct) || err != nil {
+		t.Errorf("[dir]: BuildSymlinkFarm test failed, expecting %v after running again, got %v, err %v", correct, names, err)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	var records []Record
+	SetObserver(ObserverFunc(func(r Record) {
+		records = append(records, r)
+	}))
+	defer SetObserver(nil)
+
+	p := filepath.Join(t.TempDir(), "a", "b")
+	err := MkdirP(p)
+	if len(records) != 1 || records[0].Op != "mkdir" || records[0].Path != p || err != nil {
+		t.Errorf("[dir]: Observer test failed, expecting one mkdir record for %s, got %v, err %v", p, records, err)
+	}
+
+	records = nil
+	p = filepath.Join(filepath.Dir(p), "c", "d")
+	err = MkdirPMode(p, 0755, WithMkdirOwner(os.Getuid(), os.Getgid()))
+	var ops []string
+	for _, r := range records {
+		ops = append(ops, r.Op)
+	}
+	if correct := []string{"mkdir", "chown", "chown"}; !reflect.DeepEqual(ops, correct) || err != nil {
+		t.Errorf("[dir]: Observer test failed, expecting %v records, got %v, err %v", correct, ops, err)
+	}
+}
+
+func TestWalkStats(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "a", "b"), 0755)
+	os.WriteFile(filepath.Join(d, "small"), []byte("1"), 0644)
+	os.WriteFile(filepath.Join(d, "a", "b", "large"), []byte("12345"), 0644)
+	os.Symlink("small", filepath.Join(d, "link"))
+
+	s, err := WalkStats(d)
+	correct := Stats{Files: 2, Dirs: 2, Symlinks: 1, TotalSize: 6,
+		LargestFile: filepath.Join(d, "a", "b", "large"), DeepestPath: filepath.Join(d, "a", "b", "large")}
+	if s != correct || err != nil {
+		t.Errorf("[dir]: WalkStats test failed, expecting %+v, got %+v, err %v", correct, s, err)
+	}
+}
+
+func TestOwnershipMap(t *testing.T) {
+	d := t.TempDir()
+	os.WriteFile(filepath.Join(d, "a"), nil, 0644)
+
+	m, err := OwnershipMap(d)
+	k := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	if len(m) != 1 || len(m[k]) != 2 || err != nil {
+		t.Errorf("[dir]: OwnershipMap test failed, expecting 2 paths owned by %s, got %v, err %v", k, m, err)
+	}
+
+	if err := ApplyOwnershipMap(d, m); err != nil {
+		t.Errorf("[dir]: ApplyOwnershipMap test failed with %s", err)
+	}
+	if err := ApplyOwnershipMap(d, map[string][]string{"root": {"a"}}); err == nil {
+		t.Errorf("[dir]: ApplyOwnershipMap test failed, expecting an error for an invalid owner")
+	}
+
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "b"), nil, 0644)
+	os.Symlink(outside, filepath.Join(d, "link"))
+	for _, rel := range []string{"../b", "link/b"} {
+		if err := ApplyOwnershipMap(d, map[string][]string{"1:1": {rel}}); !errors.Is(err, ErrEscape) {
+			t.Errorf("[dir]: ApplyOwnershipMap test failed, expecting ErrEscape for %s, got %v", rel, err)
+		}
+	}
+
+	if os.Getuid() != 0 {
+		return
+	}
+	os.Chmod(filepath.Join(d, "a"), 0755|os.ModeSetuid|os.ModeSetgid)
+	err = ApplyOwnershipMap(d, map[string][]string{"1:1": {"a"}})
+	info, err1 := os.Stat(filepath.Join(d, "a"))
+	if err != nil || err1 != nil || info.Mode() != 0755|os.ModeSetuid|os.ModeSetgid {
+		t.Errorf("[dir]: ApplyOwnershipMap test failed, expecting setuid and setgid kept, got %v, err %v %v", info, err, err1)
+	}
+}
+
+func TestCopyDir(t *testing.T) {
+	d := t.TempDir()
+	src := filepath.Join(d, "src")
+	dst := filepath.Join(d, "dst")
+	os.MkdirAll(filepath.Join(src, "sub"), 0750)
+	os.WriteFile(filepath.Join(src, "sub", "a"), []byte("new"), 0600)
+	os.Symlink("sub/a", filepath.Join(src, "link"))
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(filepath.Join(src, "sub", "a"), mtime, mtime)
+
+	if err := CopyDir(src, dst); err != nil {
+		t.Errorf("[dir]: CopyDir test failed with %s", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "sub", "a"))
+	if err != nil || info.Mode().Perm() != 0600 || !info.ModTime().Equal(mtime) {
+		t.Errorf("[dir]: CopyDir test failed, expecting mode 0600 and mtime %s, got %v, err %v", mtime, info, err)
+	}
+	if link, _ := os.Readlink(filepath.Join(dst, "link")); link != "sub/a" {
+		t.Errorf("[dir]: CopyDir test failed, expecting a symlink to sub/a, got %s", link)
+	}
+
+	if err := CopyDir(src, dst); !errors.Is(err, os.ErrExist) {
+		t.Errorf("[dir]: CopyDir test failed, expecting os.ErrExist, got %v", err)
+	}
+
+	// the destination is newer, so merging keeps it
+	os.WriteFile(filepath.Join(dst, "sub", "a"), []byte("kept"), 0600)
+	err = CopyDir(src, dst, WithCopyPolicy(CopyMerge))
+	if b, _ := os.ReadFile(filepath.Join(dst, "sub", "a")); string(b) != "kept" || err != nil {
+		t.Errorf("[dir]: CopyDir test failed, expecting kept, got %s, err %v", b, err)
+	}
+
+	err = CopyDir(src, dst, WithCopyPolicy(CopyOverwrite))
+	if b, _ := os.ReadFile(filepath.Join(dst, "sub", "a")); string(b) != "new" || err != nil {
+		t.Errorf("[dir]: CopyDir test failed, expecting new, got %s, err %v", b, err)
+	}
+}
+
+func TestCopyDirIntoItself(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "file"), nil, 0644)
+	dst := filepath.Join(src, "backup")
+	if err := CopyDir(src, dst); err == nil || exists(dst) {
+		t.Errorf("[dir]: CopyDir test failed, expecting an error copying into the source, got %v", err)
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	os.Symlink(src, link)
+	dst = filepath.Join(link, "a", "b")
+	if err := CopyDir(src, dst); err == nil || exists(dst) {
+		t.Errorf("[dir]: CopyDir test failed, expecting an error copying into the source through a symlink, got %v", err)
+	}
+}
+
+func TestCopyDirOverwrite(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(src, "a"), []byte("new"), 0644)
+	os.Symlink("a", filepath.Join(src, "b"))
+	os.MkdirAll(filepath.Join(dst, "a", "old"), 0755)
+	os.WriteFile(filepath.Join(dst, "b"), []byte("old"), 0644)
+
+	err := CopyDir(src, dst, WithCopyPolicy(CopyOverwrite))
+	b, _ := os.ReadFile(filepath.Join(dst, "a"))
+	link, _ := os.Readlink(filepath.Join(dst, "b"))
+	entries, _ := os.ReadDir(dst)
+	if err != nil || string(b) != "new" || link != "a" || len(entries) != 2 {
+		t.Errorf("[dir]: CopyDir test failed, expecting a and b replaced, got %q %q and %d entries, err %v", b, link, len(entries), err)
+	}
+}
+
+func TestRoot(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "root", "sub"), 0755)
+	os.WriteFile(filepath.Join(d, "root", "a"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(d, "secret"), []byte("secret"), 0644)
+	os.Symlink(filepath.Join(d, "secret"), filepath.Join(d, "root", "abs"))
+	os.Symlink("../secret", filepath.Join(d, "root", "rel"))
+
+	r, err := NewRoot(filepath.Join(d, "root"))
+	if err != nil {
+		t.Fatalf("[dir]: NewRoot test failed with %s", err)
+	}
+	defer r.Close()
+
+	for _, name := range []string{"../secret", "abs", "rel", "sub/../../secret"} {
+		if f, err := r.Open(name); !errors.Is(err, ErrEscape) {
+			if f != nil {
+				f.Close()
+			}
+			t.Errorf("[dir]: Root test failed, expecting ErrEscape for %s, got %v", name, err)
+		}
+	}
+
+	if err := r.Copy("a", "sub/b"); err != nil {
+		t.Errorf("[dir]: Root.Copy test failed with %s", err)
+	}
+	names, err := r.Ls("sub")
+	if !reflect.DeepEqual(names, []string{filepath.Join("sub", "b")}) || err != nil {
+		t.Errorf("[dir]: Root.Ls test failed, expecting [sub/b], got %v, err %v", names, err)
+	}
+
+	// removing the link must not touch what it points to
+	if err := r.Remove("rel"); err != nil {
+		t.Errorf("[dir]: Root.Remove test failed with %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(d, "secret")); err != nil {
+		t.Errorf("[dir]: Root.Remove test failed, expecting secret to be kept, got err %v", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "a", "b", "c"), 0755)
+	os.MkdirAll(filepath.Join(d, "skip"), 0755)
+	os.WriteFile(filepath.Join(d, "skip", "f"), nil, 0644)
+	os.Symlink("..", filepath.Join(d, "a", "loop"))
+
+	var paths []string
+	err := Walk(d, func(p string, info os.FileInfo) error {
+		rel, _ := filepath.Rel(d, p)
+		if rel == "skip" {
+			return SkipDir
+		}
+		paths = append(paths, rel)
+		return nil
+	}, WithMaxDepth(2), WithFollowSymlinks())
+	correct := []string{".", "a", filepath.Join("a", "b"), filepath.Join("a", "loop")}
+	if !reflect.DeepEqual(paths, correct) || err != nil {
+		t.Errorf("[dir]: Walk test failed, expecting %v, got %v, err %v", correct, paths, err)
+	}
+}
+
+func TestLsInfo(t *testing.T) {
+	d := t.TempDir()
+	os.WriteFile(filepath.Join(d, "b"), []byte("bb"), 0644)
+	os.WriteFile(filepath.Join(d, "a"), []byte("a"), 0644)
+
+	entries, err := LsInfo(d, false, false)
+	if len(entries) != 2 || entries[0].Path != filepath.Join(d, "a") || entries[1].Info.Size() != 2 || err != nil {
+		t.Errorf("[dir]: LsInfo test failed, expecting a and b of 2 bytes, got %v, err %v", entries, err)
+	}
+}
+
+func TestWalkWorkers(t *testing.T) {
+	d := t.TempDir()
+	for _, p := range []string{"a/b", "a/c", "d/e", "f"} {
+		os.MkdirAll(filepath.Join(d, p), 0755)
+		os.WriteFile(filepath.Join(d, p, "file"), nil, 0644)
+	}
+
+	var paths, paths1 []string
+	err := Walk(d, func(p string, info os.FileInfo) error {
+		paths = append(paths, p)
+		return nil
+	})
+	err1 := Walk(d, func(p string, info os.FileInfo) error {
+		paths1 = append(paths1, p)
+		return nil
+	}, WithWorkers(4))
+	if !reflect.DeepEqual(paths, paths1) || err != nil || err1 != nil {
+		t.Errorf("[dir]: Walk test failed, expecting %v with workers, got %v, err %v %v", paths, paths1, err, err1)
+	}
+}
+
+func TestCompressFiles(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "sub"), 0755)
+	os.WriteFile(filepath.Join(d, "sub", "old.log"), []byte("old"), 0640)
+	os.WriteFile(filepath.Join(d, "new.log"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(d, "keep.txt"), []byte("txt"), 0644)
+	week := time.Now().Add(-8 * 24 * time.Hour)
+	os.Chtimes(filepath.Join(d, "sub", "old.log"), week, week)
+
+	var done int
+	err := CompressFiles(d, "*.log", Gzip, false, WithOlderThan(7*24*time.Hour), WithProgress(func(_ string, n, total int) {
+		done = n
+	}))
+	info, err1 := os.Stat(filepath.Join(d, "sub", "old.log.gz"))
+	if err != nil || err1 != nil || done != 1 || info.Mode().Perm() != 0640 || !info.ModTime().Equal(week) {
+		t.Errorf("[dir]: CompressFiles test failed, expecting sub/old.log.gz with mode 0640, got %v, err %v %v", info, err, err1)
+	}
+	for _, name := range []string{"sub/old.log", "new.log.gz"} {
+		if _, err := os.Stat(filepath.Join(d, name)); !os.IsNotExist(err) {
+			t.Errorf("[dir]: CompressFiles test failed, expecting no %s, got err %v", name, err)
+		}
+	}
+}
+
+func TestCompressFilesExisting(t *testing.T) {
+	d := t.TempDir()
+	os.WriteFile(filepath.Join(d, "a.log"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(d, "a.log.gz"), []byte("mine"), 0644)
+
+	err := CompressFiles(d, "*.log", Gzip, false)
+	b, _ := os.ReadFile(filepath.Join(d, "a.log.gz"))
+	_, err1 := os.Stat(filepath.Join(d, "a.log"))
+	if err == nil || string(b) != "mine" || err1 != nil {
+		t.Errorf("[dir]: CompressFiles test failed, expecting a.log and a.log.gz kept, got %q, err %v %v", b, err, err1)
+	}
+}
+
+func TestSize(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "sub"), 0755)
+	os.WriteFile(filepath.Join(d, "a"), []byte("12345"), 0644)
+	os.WriteFile(filepath.Join(d, "sub", "b"), []byte("123"), 0644)
+	os.Symlink("a", filepath.Join(d, "link"))
+	os.Link(filepath.Join(d, "sub", "b"), filepath.Join(d, "hardlink"))
+
+	// links to files already counted count for nothing
+	for _, follow := range []bool{false, true} {
+		size, files, err := Size(d, follow)
+		if size != 8 || files != 2 || err != nil {
+			t.Errorf("[dir]: Size test failed, expecting 8 bytes in 2 files following symlinks %v, got %d in %d, err %v", follow, size, files, err)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	d := t.TempDir()
+	ch := make(chan []Event, 1)
+	stop, err := Watch(d, AllEvents, func(events []Event) {
+		ch <- events
+	}, WithDebounce(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("[dir]: Watch test failed with %s", err)
+	}
+	defer stop()
+
+	os.MkdirAll(filepath.Join(d, "sub"), 0755)
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(d, "sub", "a"), []byte("a"), 0644)
+	os.Rename(filepath.Join(d, "sub", "a"), filepath.Join(d, "b"))
+
+	var got []Event
+	select {
+	case got = <-ch:
+	case <-time.After(5 * time.Second):
+	}
+	var renamed bool
+	for _, e := range got {
+		if e.Path == filepath.Join(d, "b") && e.Op&Create != 0 && e.OldPath == filepath.Join(d, "sub", "a") {
+			renamed = true
+		}
+	}
+	if !renamed {
+		t.Errorf("[dir]: Watch test failed, expecting one batch with the rename of sub/a to b, got %v", got)
+	}
+}
+
+func TestWatchStopFromCallback(t *testing.T) {
+	d := t.TempDir()
+	stopped := make(chan error, 1)
+	var calls int32
+	// stop is only assigned once Watch returns
+	ready := make(chan struct{})
+	var stop func() error
+	stop, err := Watch(d, AllEvents, func(events []Event) {
+		<-ready
+		if atomic.AddInt32(&calls, 1) == 1 {
+			stopped <- stop()
+		}
+	})
+	if err != nil {
+		t.Fatalf("[dir]: Watch test failed with %s", err)
+	}
+	close(ready)
+
+	os.WriteFile(filepath.Join(d, "a"), nil, 0644)
+	os.WriteFile(filepath.Join(d, "b"), nil, 0644)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("[dir]: Watch test failed, expecting stop to succeed, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[dir]: Watch test failed, expecting the watch stopped from its callback")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("[dir]: Watch test failed, expecting no call after stop, got %d calls", n)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	d := t.TempDir()
+	for _, root := range []string{"a", "b"} {
+		os.MkdirAll(filepath.Join(d, root, "sub"), 0755)
+		os.WriteFile(filepath.Join(d, root, "sub", "f"), []byte("f"), 0644)
+		os.Symlink("sub/f", filepath.Join(d, root, "link"))
+	}
+
+	a, err := Checksum(filepath.Join(d, "a"))
+	b, err1 := Checksum(filepath.Join(d, "b"))
+	if a != b || err != nil || err1 != nil {
+		t.Errorf("[dir]: Checksum test failed, expecting identical trees to match, got %s %s, err %v %v", a, b, err, err1)
+	}
+
+	os.Chmod(filepath.Join(d, "b", "sub", "f"), 0600)
+	if b, _ = Checksum(filepath.Join(d, "b")); a == b {
+		t.Errorf("[dir]: Checksum test failed, expecting a mode change to change the checksum")
+	}
+}
+
+func TestEnforceQuota(t *testing.T) {
+	d := t.TempDir()
+	for i, name := range []string{"old", "mid", "new"} {
+		p := filepath.Join(d, name)
+		os.WriteFile(p, []byte("12345"), 0644)
+		mtime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		os.Chtimes(p, mtime, mtime)
+	}
+
+	err := EnforceQuota(d, 10, EvictOldest)
+	_, err1 := os.Stat(filepath.Join(d, "old"))
+	_, err2 := os.Stat(filepath.Join(d, "mid"))
+	if err != nil || !os.IsNotExist(err1) || err2 != nil {
+		t.Errorf("[dir]: EnforceQuota test failed, expecting only old to be removed, got err %v %v %v", err, err1, err2)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	d := t.TempDir()
+	for _, root := range []string{"a", "b"} {
+		os.MkdirAll(filepath.Join(d, root), 0755)
+		os.WriteFile(filepath.Join(d, root, "same"), []byte("same"), 0644)
+		os.WriteFile(filepath.Join(d, root, "changed"), []byte(root), 0644)
+	}
+	os.WriteFile(filepath.Join(d, "a", "removed"), nil, 0644)
+	os.WriteFile(filepath.Join(d, "b", "added"), nil, 0644)
+	os.Chmod(filepath.Join(d, "b", "same"), 0600)
+
+	entries, err := Diff(filepath.Join(d, "a"), filepath.Join(d, "b"))
+	correct := []DiffEntry{{"added", Added}, {"changed", Modified}, {"removed", Removed}, {"same", ModeChanged}}
+	if !reflect.DeepEqual(entries, correct) || err != nil {
+		t.Errorf("[dir]: Diff test failed, expecting %v, got %v, err %v", correct, entries, err)
+	}
+}
+
+func TestGlobStar(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "src", "a", "b"), 0755)
+	for _, f := range []string{"x.go", "a/y.go", "a/b/z.go", "a/b/w.txt"} {
+		os.WriteFile(filepath.Join(d, "src", f), nil, 0644)
+	}
+	correct := []string{filepath.Join(d, "src", "a", "b", "z.go"), filepath.Join(d, "src", "a", "y.go"), filepath.Join(d, "src", "x.go")}
+	if result, err := Glob("src/**/*.go", d); !reflect.DeepEqual(result, correct) || err != nil {
+		t.Errorf("[dir]: Glob globstar test failed, expecting %s, got %s, err %v", correct, result, err)
+	}
+	correct = correct[1:]
+	if result, err := Glob("**/*.go", d, "**/b/*"); !reflect.DeepEqual(result, correct) || err != nil {
+		t.Errorf("[dir]: Glob globstar test failed, expecting %s, got %s, err %v", correct, result, err)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	ign, _ := ParseIgnore(strings.NewReader("# comment\n*.log\n!keep.log\nbuild/\n/root.txt\ndocs/**/*.tmp\n"))
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.log", false, true},
+		{"sub/a.log", false, true},
+		{"sub/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build/main.go", false, true},
+		{"root.txt", false, true},
+		{"sub/root.txt", false, false},
+		{"docs/a/b/c.tmp", false, true},
+		{"src/c.tmp", false, false},
+	}
+	for _, v := range tests {
+		if ign.Ignored(v.path, v.isDir) != v.ignored {
+			t.Errorf("[dir]: Ignore test failed, expecting %s ignored %t", v.path, v.ignored)
+		}
+	}
+
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "build"), 0755)
+	for _, f := range []string{"a.go", "a.log", "build/b.go"} {
+		os.WriteFile(filepath.Join(d, f), nil, 0644)
+	}
+	correct := []string{filepath.Join(d, "a.go")}
+	if result, err := Glob("**/*", d, ign); !reflect.DeepEqual(result, correct) || err != nil {
+		t.Errorf("[dir]: Glob with Ignore test failed, expecting %s, got %s, err %v", correct, result, err)
+	}
+	var walked []string
+	Walk(d, func(p string, info os.FileInfo) error {
+		walked = append(walked, p)
+		return nil
+	}, WithWalkMatcher(ign.Matcher(d)))
+	correct = []string{d, filepath.Join(d, "a.go")}
+	if !reflect.DeepEqual(walked, correct) {
+		t.Errorf("[dir]: Walk with Ignore test failed, expecting %s, got %s", correct, walked)
+	}
+}
+
+func TestMkdirPMode(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "a", "b")
+	if err := MkdirPMode(p, 0700, WithMkdirOwner(os.Getuid(), os.Getgid())); err != nil {
+		t.Errorf("[dir]: MkdirPMode test failed with %s", err)
+	}
+	if info, err := os.Stat(p); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("[dir]: MkdirPMode test failed, expecting mode 0700, got %v, err %v", info, err)
+	}
+	if err := MkdirP(p); err != nil {
+		t.Errorf("[dir]: MkdirP test failed, expecting success on an existing directory, got %s", err)
+	}
+	os.WriteFile(filepath.Join(d, "file"), nil, 0644)
+	if err := MkdirP(filepath.Join(d, "file")); err == nil {
+		t.Errorf("[dir]: MkdirP test failed, expecting an error on an existing file")
+	}
+}
+
+func TestPruneEmpty(t *testing.T) {
+	d := t.TempDir()
+	os.MkdirAll(filepath.Join(d, "a", "b"), 0755)
+	os.MkdirAll(filepath.Join(d, "c"), 0755)
+	os.WriteFile(filepath.Join(d, "c", "file"), nil, 0644)
+
+	if empty, err := IsEmpty(filepath.Join(d, "a", "b")); !empty || err != nil {
+		t.Errorf("[dir]: IsEmpty test failed, expecting true, got %t, err %v", empty, err)
+	}
+	correct := []string{filepath.Join(d, "a", "b"), filepath.Join(d, "a")}
+	if pruned, err := PruneEmpty(d, true); !reflect.DeepEqual(pruned, correct) || err != nil {
+		t.Errorf("[dir]: PruneEmpty dry run test failed, expecting %s, got %s, err %v", correct, pruned, err)
+	}
+	if _, err := os.Stat(filepath.Join(d, "a", "b")); err != nil {
+		t.Errorf("[dir]: PruneEmpty dry run test failed, expecting nothing removed, got %s", err)
+	}
+	if pruned, err := PruneEmpty(d, false); !reflect.DeepEqual(pruned, correct) || err != nil {
+		t.Errorf("[dir]: PruneEmpty test failed, expecting %s, got %s, err %v", correct, pruned, err)
+	}
+	if _, err := os.Stat(filepath.Join(d, "a")); !os.IsNotExist(err) {
+		t.Errorf("[dir]: PruneEmpty test failed, expecting a removed, got %v", err)
+	}
+}
+
+func TestTempDir(t *testing.T) {
+	var records []Record
+	SetObserver(ObserverFunc(func(r Record) {
+		records = append(records, r)
+	}))
+	defer SetObserver(nil)
+
+	var p string
+	func() {
+		defer func() { recover() }()
+		WithTempDir("dirtest", func(dir string) error {
+			p = dir
+			panic("boom")
+		})
+	}()
+	if _, err := os.Stat(p); len(p) == 0 || !os.IsNotExist(err) {
+		t.Errorf("[dir]: WithTempDir test failed, expecting %s removed after a panic, got %v", p, err)
+	}
+	if len(records) != 2 || records[0].Op != "mkdir" || records[1].Op != "remove" || records[1].Path != p {
+		t.Errorf("[dir]: WithTempDir test failed, expecting mkdir and remove records for %s, got %v", p, records)
+	}
+
+	p, _, err := SecureTempDir("dirtest")
+	if err != nil {
+		t.Fatalf("[dir]: SecureTempDir test failed with %s", err)
+	}
+	defer os.Remove(filepath.Dir(p))
+	if info, err := os.Stat(filepath.Dir(p)); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("[dir]: SecureTempDir test failed, expecting a 0700 parent, got %v, err %v", info, err)
+	}
+	if err := CleanupTempDirs(); err != nil {
+		t.Errorf("[dir]: CleanupTempDirs test failed with %s", err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("[dir]: CleanupTempDirs test failed, expecting %s removed, got %v", p, err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+	defer func() { rename = os.Rename }()
+
+	d := t.TempDir()
+	src := filepath.Join(d, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0750)
+	os.WriteFile(filepath.Join(src, "sub", "file"), []byte("content"), 0600)
+	os.Symlink("sub/file", filepath.Join(src, "link"))
+	sum, _ := Checksum(src)
+
+	dst := filepath.Join(d, "dst")
+	if err := Move(src, dst); err != nil {
+		t.Errorf("[dir]: Move test failed with %s", err)
+	}
+	if sum1, err := Checksum(dst); sum1 != sum || err != nil {
+		t.Errorf("[dir]: Move test failed, expecting checksum %s, got %s, err %v", sum, sum1, err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Errorf("[dir]: Move test failed, expecting %s removed, got %v", src, err)
+	}
+
+	os.MkdirAll(src, 0755)
+	if err := Move(src, dst); !errors.Is(err, os.ErrExist) {
+		t.Errorf("[dir]: Move test failed, expecting os.ErrExist, got %v", err)
+	}
+}
+
+func TestMoveStaged(t *testing.T) {
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+	defer func() { rename = os.Rename }()
+	var records []Record
+	SetObserver(ObserverFunc(func(r Record) {
+		records = append(records, r)
+	}))
+	defer SetObserver(nil)
+
+	d := t.TempDir()
+	src, dst := filepath.Join(d, "src"), filepath.Join(d, "dst")
+	os.WriteFile(src, []byte("new"), 0644)
+	os.WriteFile(dst, []byte("old"), 0644)
+	if err := Move(src, dst); err != nil {
+		t.Errorf("[dir]: Move test failed with %s", err)
+	}
+	if b, _ := os.ReadFile(dst); string(b) != "new" {
+		t.Errorf("[dir]: Move test failed, expecting dst replaced, got %s", b)
+	}
+	if names, _ := readDirNames(d); !reflect.DeepEqual(names, []string{"dst"}) {
+		t.Errorf("[dir]: Move test failed, expecting the stage removed, got %v", names)
+	}
+	last := records[len(records)-1]
+	if last.Op != "move" || last.Path != dst || last.Err != nil {
+		t.Errorf("[dir]: Move test failed, expecting a move record for %s, got %v", dst, last)
+	}
+}
+
+func TestSafeRemove(t *testing.T) {
+	d := t.TempDir()
+	p := filepath.Join(d, "a")
+	os.MkdirAll(filepath.Join(p, "b"), 0755)
+	os.WriteFile(filepath.Join(p, "b", "file"), nil, 0644)
+
+	if _, err := SafeRemove("/", WithRemoveDryRun()); !errors.Is(err, ErrProtected) {
+		t.Errorf("[dir]: SafeRemove test failed, expecting ErrProtected for /, got %v", err)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if _, err := SafeRemove(home, WithRemoveDryRun()); !errors.Is(err, ErrProtected) {
+			t.Errorf("[dir]: SafeRemove test failed, expecting ErrProtected for %s, got %v", home, err)
+		}
+	}
+	if _, err := SafeRemove(p, WithSandbox(filepath.Join(d, "other"))); !errors.Is(err, ErrEscape) {
+		t.Errorf("[dir]: SafeRemove test failed, expecting ErrEscape, got %v", err)
+	}
+
+	correct := []string{filepath.Join(p, "b", "file"), filepath.Join(p, "b"), p}
+	if paths, err := SafeRemove(p, WithRemoveDryRun()); !reflect.DeepEqual(paths, correct) || err != nil || !exists(p) {
+		t.Errorf("[dir]: SafeRemove dry run test failed, expecting %s, got %s, err %v", correct, paths, err)
+	}
+	if paths, err := SafeRemove(p, WithSandbox(d)); !reflect.DeepEqual(paths, correct) || err != nil || exists(p) {
+		t.Errorf("[dir]: SafeRemove test failed, expecting %s, got %s, err %v", correct, paths, err)
+	}
+}
+
+func TestSafeRemoveHomeParent(t *testing.T) {
+	d := t.TempDir()
+	home := filepath.Join(d, "users", "me")
+	os.MkdirAll(home, 0755)
+	old, ok := os.LookupEnv("HOME")
+	os.Setenv("HOME", home)
+	defer func() {
+		if ok {
+			os.Setenv("HOME", old)
+		} else {
+			os.Unsetenv("HOME")
+		}
+	}()
+
+	if _, err := SafeRemove(filepath.Dir(home)); !errors.Is(err, ErrProtected) || !exists(home) {
+		t.Errorf("[dir]: SafeRemove test failed, expecting ErrProtected for the parent of $HOME, got %v", err)
+	}
+	if _, err := SafeRemove(filepath.Dir(home), WithRemoveForce()); err != nil || exists(home) {
+		t.Errorf("[dir]: SafeRemove test failed, expecting the parent of $HOME removed when forced, got %v", err)
+	}
+}
+
+func TestParseOwnerLine(t *testing.T) {
+	tests := []struct {
+		pm, line, path, pkg string
+		ok                  bool
+	}{
+		{"rpm", "/usr/bin/my tool\tmytool", "/usr/bin/my tool", "mytool", true},
+		{"rpm", "file /tmp/x is not owned by any package", "", "", false},
+		{"dpkg", "libc6:amd64, libc6-dev:amd64: /usr/lib/x86_64-linux-gnu/libc.so", "/usr/lib/x86_64-linux-gnu/libc.so", "libc6", true},
+		{"dpkg", "dpkg: /usr/share/my docs/a b", "/usr/share/my docs/a b", "dpkg", true},
+		{"dpkg", "diversion by dash from: /bin/sh", "", "", false},
+	}
+	for _, tc := range tests {
+		path, pkg, ok := parseOwnerLine(tc.pm, tc.line)
+		if path != tc.path || pkg != tc.pkg || ok != tc.ok {
+			t.Errorf("[dir]: parseOwnerLine test failed for %q, expecting %q %q %v, got %q %q %v", tc.line, tc.path, tc.pkg, tc.ok, path, pkg, ok)
+		}
+	}
+}
+
+func TestParseVerifyLine(t *testing.T) {
+	tests := []struct {
+		line, path string
+		ok         bool
+	}{
+		{"S.5....T.  c /etc/my app.conf", "/etc/my app.conf", true},
+		{"??5?????? c /etc/foo", "/etc/foo", true},
+		{"??5??????   /usr/share/a b", "/usr/share/a b", true},
+		{".M.......    /usr/bin/foo", "", false},
+		{"missing     /usr/bin/foo", "", false},
+	}
+	for _, tc := range tests {
+		if path, ok := parseVerifyLine(tc.line); path != tc.path || ok != tc.ok {
+			t.Errorf("[dir]: parseVerifyLine test failed for %q, expecting %q %v, got %q %v", tc.line, tc.path, tc.ok, path, ok)
+		}
+	}
+}
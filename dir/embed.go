@@ -0,0 +1,122 @@
+package dir
+
+import (
+	"bytes"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type extractOptions struct {
+	manifest map[string]os.FileMode
+	fileMode os.FileMode
+	dirMode  os.FileMode
+}
+
+// ExtractOption configures ExtractEmbedded
+type ExtractOption func(*extractOptions)
+
+// WithManifest set the modes of the extracted files, keys are paths relative to root
+func WithManifest(manifest map[string]os.FileMode) ExtractOption {
+	return func(o *extractOptions) {
+		o.manifest = manifest
+	}
+}
+
+// WithDefaultModes set the modes of files and directories not listed in the manifest
+func WithDefaultModes(file, dir os.FileMode) ExtractOption {
+	return func(o *extractOptions) {
+		o.fileMode = file
+		o.dirMode = dir
+	}
+}
+
+// ExtractEmbedded write the tree under root of src (usually an embed.FS) to dest.
+// Every file is written to a temporary file and renamed into place, files
+// already having the same content and mode are left untouched, so it is safe
+// to run on every start of a program.
+func ExtractEmbedded(src fs.FS, root, dest string, opts ...ExtractOption) error {
+	o := extractOptions{fileMode: 0644, dirMode: 0755}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return fs.WalkDir(src, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := p
+		if root != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+			if len(rel) == 0 {
+				rel = "."
+			}
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+
+		if d.IsDir() {
+			mode := o.dirMode
+			if m, ok := o.manifest[rel]; ok {
+				mode = m
+			}
+			start := time.Now()
+			err := os.MkdirAll(target, mode)
+			if err == nil {
+				err = os.Chmod(target, mode)
+			}
+			observe("mkdir", target, start, 0, err)
+			return err
+		}
+
+		mode := o.fileMode
+		if m, ok := o.manifest[rel]; ok {
+			mode = m
+		}
+
+		b, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(target, b, mode)
+	})
+}
+
+// writeFileAtomic write b to a temporary file and rename it to target,
+// nothing is done if target already has the content and mode.
+func writeFileAtomic(target string, b []byte, mode os.FileMode) error {
+	if fi, err := os.Lstat(target); err == nil && fi.Mode().IsRegular() && fi.Mode().Perm() == mode.Perm() {
+		old, err := ioutil.ReadFile(target)
+		if err == nil && bytes.Equal(old, b) {
+			return nil
+		}
+	}
+
+	start := time.Now()
+	f, err := ioutil.TempFile(filepath.Dir(target), "."+filepath.Base(target))
+	if err != nil {
+		observe("write", target, start, 0, err)
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(b)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp, target)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	observe("write", target, start, int64(len(b)), err)
+	return err
+}
@@ -0,0 +1,51 @@
+package dir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExtractEmbedded(t *testing.T) {
+	src := fstest.MapFS{
+		"assets/bin/run.sh":   &fstest.MapFile{Data: []byte("#!/bin/sh\n")},
+		"assets/conf/app.ini": &fstest.MapFile{Data: []byte("[app]\n")},
+	}
+	dest := t.TempDir()
+	manifest := map[string]os.FileMode{"bin/run.sh": 0755}
+
+	for i := 0; i < 2; i++ {
+		if err := ExtractEmbedded(src, "assets", dest, WithManifest(manifest)); err != nil {
+			t.Fatalf("[dir]: ExtractEmbedded test failed with %s", err)
+		}
+	}
+
+	fi, err := os.Stat(filepath.Join(dest, "bin", "run.sh"))
+	if err != nil || fi.Mode().Perm() != 0755 {
+		t.Errorf("[dir]: ExtractEmbedded test failed, expecting mode 0755, got %v, err %v", fi, err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "conf", "app.ini"))
+	if err != nil || string(b) != "[app]\n" {
+		t.Errorf("[dir]: ExtractEmbedded test failed, expecting [app], got %s, err %v", b, err)
+	}
+}
+
+func TestExtractEmbeddedDot(t *testing.T) {
+	src := fstest.MapFS{
+		"a":     &fstest.MapFile{Data: []byte("a")},
+		"b/c.d": &fstest.MapFile{Data: []byte("c")},
+	}
+	dest := t.TempDir()
+	if err := ExtractEmbedded(src, ".", dest); err != nil {
+		t.Fatalf("[dir]: ExtractEmbedded test failed with %s", err)
+	}
+	for p, content := range map[string]string{"a": "a", "b/c.d": "c"} {
+		b, err := ioutil.ReadFile(filepath.Join(dest, filepath.FromSlash(p)))
+		if err != nil || string(b) != content {
+			t.Errorf("[dir]: ExtractEmbedded test failed, expecting %s in %s, got %s, err %v", content, p, b, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package dir
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// IsEmpty whether the directory path has no entries
+func IsEmpty(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+// PruneEmpty remove the directories under root, root excluded, that are empty
+// or only hold empty directories, bottom-up, and return them in the order
+// they were removed. With dryRun nothing is removed, the returned directories
+// are the ones that would be. Symlinks to directories are not followed.
+func PruneEmpty(root string, dryRun bool) ([]string, error) {
+	var pruned []string
+	_, err := pruneEmpty(root, dryRun, &pruned)
+	return pruned, err
+}
+
+// pruneEmpty prune the empty directories under dir and return whether dir is empty afterwards
+func pruneEmpty(dir string, dryRun bool, pruned *[]string) (bool, error) {
+	names, err := readDirNames(dir)
+	if err != nil {
+		return false, err
+	}
+
+	empty := true
+	for _, n := range names {
+		p := filepath.Join(dir, n)
+		info, err := os.Lstat(p)
+		if err != nil {
+			return false, err
+		}
+		if !info.IsDir() {
+			empty = false
+			continue
+		}
+		ok, err := pruneEmpty(p, dryRun, pruned)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			empty = false
+			continue
+		}
+		if !dryRun {
+			start := time.Now()
+			err = os.Remove(p)
+			observe("remove", p, start, 0, err)
+			if err != nil {
+				return false, err
+			}
+		}
+		*pruned = append(*pruned, p)
+	}
+	return empty, nil
+}
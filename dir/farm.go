@@ -0,0 +1,171 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrCollision two targets, or a target and an existing file, want the same link name
+var ErrCollision = errors.New("Link name collision")
+
+// CollisionPolicy decides what BuildSymlinkFarm does when a link name is taken
+type CollisionPolicy int
+
+const (
+	// CollisionError fail with ErrCollision
+	CollisionError CollisionPolicy = iota
+	// CollisionSkip keep what is there
+	CollisionSkip
+	// CollisionReplace replace what is there, if it is not a directory
+	CollisionReplace
+	// CollisionRename pick another name like UniquePath, reusing the one
+	// already linking to the target from a previous run
+	CollisionRename
+)
+
+type farmOptions struct {
+	policy   CollisionPolicy
+	relative bool
+	name     func(target string) string
+}
+
+// FarmOption configures BuildSymlinkFarm
+type FarmOption func(*farmOptions)
+
+// WithCollisionPolicy handle taken link names with p instead of CollisionError
+func WithCollisionPolicy(p CollisionPolicy) FarmOption {
+	return func(o *farmOptions) {
+		o.policy = p
+	}
+}
+
+// WithRelativeLinks make the links relative to the farm instead of absolute
+func WithRelativeLinks() FarmOption {
+	return func(o *farmOptions) {
+		o.relative = true
+	}
+}
+
+// WithLinkName name the link to target fn(target) instead of its base name
+func WithLinkName(fn func(target string) string) FarmOption {
+	return func(o *farmOptions) {
+		o.name = fn
+	}
+}
+
+// BuildSymlinkFarm create destDir holding a symlink to each of targets, eg:
+// to assemble an alternatives-style directory or a plugin tree. Links already
+// pointing to their target are left alone, so it can be run again after
+// adding targets.
+func BuildSymlinkFarm(targets []string, destDir string, opts ...FarmOption) error {
+	o := farmOptions{name: filepath.Base}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+
+	created := make(map[string]struct{})
+	for _, t := range targets {
+		abs, err := filepath.Abs(t)
+		if err != nil {
+			return err
+		}
+		dest := abs
+		if o.relative {
+			dest, err = filepath.Rel(absDir, abs)
+			if err != nil {
+				return err
+			}
+		}
+
+		link := filepath.Join(destDir, o.name(t))
+		if old, err := os.Readlink(link); err == nil && old == dest {
+			created[link] = struct{}{}
+			continue
+		}
+
+		_, err = os.Lstat(link)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			_, ours := created[link]
+			switch {
+			case o.policy == CollisionSkip:
+				continue
+			case o.policy == CollisionRename:
+				var linked bool
+				link, linked, err = renamedLink(link, dest)
+				if err != nil {
+					return err
+				}
+				if linked {
+					created[link] = struct{}{}
+					continue
+				}
+			case o.policy == CollisionReplace && !ours:
+				// symlink next to it and rename over it, so the name never disappears
+				tmp, err := UniquePath(link + ".tmp")
+				if err != nil {
+					return err
+				}
+				start := time.Now()
+				err = os.Symlink(dest, tmp)
+				if err == nil {
+					if err = os.Rename(tmp, link); err != nil {
+						os.Remove(tmp)
+					}
+				}
+				observe("symlink", link, start, 0, err)
+				if err != nil {
+					return err
+				}
+				created[link] = struct{}{}
+				continue
+			default:
+				return fmt.Errorf("%w: %s for %s", ErrCollision, link, t)
+			}
+		}
+
+		start := time.Now()
+		err = os.Symlink(dest, link)
+		observe("symlink", link, start, 0, err)
+		if err != nil {
+			return err
+		}
+		created[link] = struct{}{}
+	}
+
+	return nil
+}
+
+// renamedLink the first numbered alternative of link already linking to dest,
+// with linked true, or else the first free one
+func renamedLink(link, dest string) (string, bool, error) {
+	d, name := filepath.Split(link)
+	base, ext := splitExt(name)
+	for n := 1; n < 10000; n++ {
+		p := filepath.Join(d, NumberedScheme(base, ext, n))
+		_, err := os.Lstat(p)
+		if os.IsNotExist(err) {
+			return p, false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if old, err := os.Readlink(p); err == nil && old == dest {
+			return p, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("no unique path found for %s", link)
+}
@@ -0,0 +1,137 @@
+package dir
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Ignore decides which paths to skip with the rules of .gitignore files:
+// "#" comments, "!" negations re-including paths, patterns ending with "/"
+// matching directories only, patterns with a leading or middle "/" anchored
+// to the root and "**" matching any number of directories. The last matching
+// pattern wins, and nothing under an ignored directory can be re-included.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	patt    []string
+	negate  bool
+	dirOnly bool
+}
+
+// NewIgnore return an Ignore with the patterns, one per .gitignore line
+func NewIgnore(patterns ...string) *Ignore {
+	ign := &Ignore{}
+	for _, p := range patterns {
+		ign.Add(p)
+	}
+	return ign
+}
+
+// ParseIgnore read an Ignore from the lines of r
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	ign := &Ignore{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ign.Add(scanner.Text())
+	}
+	return ign, scanner.Err()
+}
+
+// ReadIgnore read an Ignore from the pattern file, eg: ".gitignore"
+func ReadIgnore(file string) (*Ignore, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseIgnore(f)
+}
+
+// Add append the pattern of a .gitignore line, blank lines and comments are skipped
+func (ign *Ignore) Add(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if len(line) == 0 || line[0] == '#' {
+		return
+	}
+
+	var r ignoreRule
+	if line[0] == '!' {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if len(line) == 0 {
+		return
+	}
+
+	// a pattern without a leading or middle slash matches at any level
+	if !strings.Contains(line, "/") {
+		r.patt = []string{"**", line}
+	} else {
+		r.patt = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	}
+	// a trailing "**" matches everything inside, but not the directory itself
+	if r.patt[len(r.patt)-1] == "**" {
+		r.patt = append(r.patt, "*")
+	}
+	ign.rules = append(ign.rules, r)
+}
+
+// Ignored whether the path, relative to the directory the patterns apply to, is ignored
+func (ign *Ignore) Ignored(path string, isDir bool) bool {
+	p := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for i := 1; i < len(p); i++ {
+		if ign.match(p[:i], true) {
+			return true
+		}
+	}
+	return ign.match(p, isDir)
+}
+
+func (ign *Ignore) match(p []string, isDir bool) bool {
+	ignored := false
+	for _, r := range ign.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if matchComponents(r.patt, p, false) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// Matcher a PathMatcher for the paths relative to root that are not ignored,
+// to be passed to WithWalkMatcher, WithCopyMatcher or WithStatsMatcher.
+// Ignored directories are not descended into.
+func (ign *Ignore) Matcher(root string) PathMatcher {
+	return ignoreMatcher{ign, root}
+}
+
+type ignoreMatcher struct {
+	ign  *Ignore
+	root string
+}
+
+func (m ignoreMatcher) Match(path string) bool {
+	info, err := os.Lstat(filepath.Join(m.root, path))
+	return !m.ign.Ignored(path, err == nil && info.IsDir())
+}
+
+func (m ignoreMatcher) Descend(dir string) bool {
+	return !m.ign.Ignored(dir, true)
+}
@@ -0,0 +1,150 @@
+package dir
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PathMatcher decides whether a path is wanted
+type PathMatcher interface {
+	Match(path string) bool
+}
+
+// GlobMatcher match paths against shell glob patterns. Besides the syntax of
+// filepath.Match it understands "{a,b}" alternatives and "**" matching any
+// number of directories, eg: "/src/**/*.{go,c}".
+type GlobMatcher struct {
+	patterns [][]string
+}
+
+// NewGlobMatcher return a GlobMatcher matching any of the patterns
+func NewGlobMatcher(patterns ...string) (*GlobMatcher, error) {
+	m := &GlobMatcher{}
+	for _, p := range patterns {
+		for _, v := range expandBraces(p) {
+			// validate the pattern
+			if _, err := filepath.Match(v, ""); err != nil {
+				return nil, err
+			}
+			m.patterns = append(m.patterns, splitPath(filepath.Clean(v)))
+		}
+	}
+	return m, nil
+}
+
+// Match whether path matches one of the patterns
+func (m *GlobMatcher) Match(path string) bool {
+	p := splitPath(filepath.Clean(path))
+	for _, v := range m.patterns {
+		if matchComponents(v, p, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// Descend whether paths under the directory dir can match one of the patterns,
+// so that walkers and watchers can skip the directories that can't.
+func (m *GlobMatcher) Descend(dir string) bool {
+	p := splitPath(filepath.Clean(dir))
+	for _, v := range m.patterns {
+		if matchComponents(v, p, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// Root the longest leading directory without pattern characters shared by all patterns
+func (m *GlobMatcher) Root() string {
+	var root []string
+	for i, v := range m.patterns {
+		var static []string
+		for _, c := range v[:len(v)-1] {
+			if strings.ContainsAny(c, "*?[\\") {
+				break
+			}
+			static = append(static, c)
+		}
+		if i == 0 {
+			root = static
+			continue
+		}
+		n := 0
+		for n < len(root) && n < len(static) && root[n] == static[n] {
+			n++
+		}
+		root = root[:n]
+	}
+	if len(root) == 0 {
+		return "."
+	}
+	if len(root) == 1 && root[0] == "" {
+		return string(filepath.Separator)
+	}
+	return strings.Join(root, string(filepath.Separator))
+}
+
+// matchComponents match the path components p against the pattern components patt,
+// if prefix is true p only needs to match the beginning of patt.
+func matchComponents(patt, p []string, prefix bool) bool {
+	for len(patt) > 0 {
+		if patt[0] == "**" {
+			// "**" matches zero or more components
+			for i := 0; i <= len(p); i++ {
+				if matchComponents(patt[1:], p[i:], prefix) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(p) == 0 {
+			return prefix
+		}
+		ok, _ := filepath.Match(patt[0], p[0])
+		if !ok {
+			return false
+		}
+		patt, p = patt[1:], p[1:]
+	}
+	return len(p) == 0
+}
+
+func splitPath(p string) []string {
+	return strings.Split(p, string(filepath.Separator))
+}
+
+// expandBraces expand "a{b,c}d" to "abd" and "acd", nested braces included
+func expandBraces(p string) []string {
+	start := strings.Index(p, "{")
+	if start < 0 {
+		return []string{p}
+	}
+
+	depth := 0
+	var alts []string
+	last := start + 1
+	for i := start; i < len(p); i++ {
+		switch p[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alts = append(alts, p[last:i])
+				last = i + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				alts = append(alts, p[last:i])
+				var res []string
+				for _, a := range alts {
+					res = append(res, expandBraces(p[:start]+a+p[i+1:])...)
+				}
+				return res
+			}
+		}
+	}
+	// unclosed brace, take it literally
+	return []string{p}
+}
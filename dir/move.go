@@ -0,0 +1,103 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// rename is os.Rename, replaced by the tests to simulate crossing file systems
+var rename = os.Rename
+
+// Move rename src to dst like os.Rename, falling back to copying and deleting
+// when they are on different file systems, eg: from a /tmp mounted as tmpfs.
+// The copy keeps the permissions, timestamps and, when allowed, the owners of
+// src. It is staged next to dst and verified against src before it is renamed
+// into place and src is removed, so a failed copy leaves src and dst untouched.
+func Move(src, dst string) (err error) {
+	start := time.Now()
+	defer func() {
+		observe("move", dst, start, 0, err)
+	}()
+
+	err = rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if dinfo, err := os.Lstat(dst); err == nil && (info.IsDir() || dinfo.IsDir()) {
+		return &os.LinkError{Op: "move", Old: src, New: dst, Err: os.ErrExist}
+	}
+
+	stage, err := ioutil.TempDir(filepath.Dir(dst), "."+filepath.Base(dst)+".move")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+
+	staged := filepath.Join(stage, filepath.Base(dst))
+	if err := moveCopy(src, staged, info); err != nil {
+		return err
+	}
+	// the stage is on the file system of dst, this rename doesn't cross
+	if err := os.Rename(staged, dst); err != nil {
+		return err
+	}
+
+	rmStart := time.Now()
+	err = os.RemoveAll(src)
+	observe("remove", src, rmStart, 0, err)
+	return err
+}
+
+// moveCopy copy src to dst and verify the copy
+func moveCopy(src, dst string, info os.FileInfo) error {
+	var err error
+	switch {
+	case info.IsDir():
+		err = CopyDir(src, dst)
+	case info.Mode()&os.ModeSymlink != 0:
+		err = copySymlink(src, dst)
+	case info.Mode().IsRegular():
+		err = copyFile(src, dst, info)
+	default:
+		return fmt.Errorf("move %s: can't copy %s across file systems", src, info.Mode().Type())
+	}
+	if err != nil {
+		return err
+	}
+	return verifyMove(src, dst)
+}
+
+// verifyMove keep the owners of src on dst when allowed, and check they are identical
+func verifyMove(src, dst string) error {
+	// owners can only be kept with enough privileges
+	m, err := OwnershipMap(src)
+	if err == nil {
+		err = ApplyOwnershipMap(dst, m)
+	}
+	if err != nil && !errors.Is(err, ErrNoOwnership) && !errors.Is(err, os.ErrPermission) {
+		return err
+	}
+
+	sum, err := Checksum(src)
+	if err != nil {
+		return err
+	}
+	sum1, err := Checksum(dst)
+	if err != nil {
+		return err
+	}
+	if sum != sum1 {
+		return fmt.Errorf("move %s: the copy at %s does not match", src, dst)
+	}
+	return nil
+}
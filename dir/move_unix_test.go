@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestMoveFailure(t *testing.T) {
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+	defer func() { rename = os.Rename }()
+
+	d := t.TempDir()
+	src := filepath.Join(d, "src")
+	os.MkdirAll(src, 0755)
+	// fifos are not copied, the verification fails
+	if err := syscall.Mkfifo(filepath.Join(src, "fifo"), 0644); err != nil {
+		t.Skipf("[dir]: Move test skipped, can't create a fifo: %s", err)
+	}
+
+	if err := Move(src, filepath.Join(d, "dst")); err == nil {
+		t.Errorf("[dir]: Move test failed, expecting a verification error")
+	}
+	if names, _ := readDirNames(d); !reflect.DeepEqual(names, []string{"src"}) {
+		t.Errorf("[dir]: Move test failed, expecting src untouched and no copy left, got %v", names)
+	}
+}
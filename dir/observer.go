@@ -0,0 +1,54 @@
+package dir
+
+import (
+	"sync"
+	"time"
+)
+
+// Record one operation of a mutating function of this package, as reported to the Observer
+type Record struct {
+	// Op the operation, eg: "mkdir", "write", "chmod", "chown", "symlink"
+	Op   string
+	Path string
+	// Bytes the number of bytes written, 0 if not relevant
+	Bytes    int64
+	Duration time.Duration
+	Err      error
+}
+
+// Observer receives a Record for every operation the mutating functions of
+// this package perform, eg: to log or meter file system activity.
+// Observe must be safe for concurrent use.
+type Observer interface {
+	Observe(Record)
+}
+
+// ObserverFunc turns a function into an Observer
+type ObserverFunc func(Record)
+
+// Observe call fn with r
+func (fn ObserverFunc) Observe(r Record) {
+	fn(r)
+}
+
+var (
+	observerMu sync.RWMutex
+	observer   Observer
+)
+
+// SetObserver report the operations to o from now on, nil stops reporting
+func SetObserver(o Observer) {
+	observerMu.Lock()
+	observer = o
+	observerMu.Unlock()
+}
+
+// observe report the operation op on path that started at start to the Observer, if any
+func observe(op, path string, start time.Time, bytes int64, err error) {
+	observerMu.RLock()
+	o := observer
+	observerMu.RUnlock()
+	if o != nil {
+		o.Observe(Record{Op: op, Path: path, Bytes: bytes, Duration: time.Since(start), Err: err})
+	}
+}
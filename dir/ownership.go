@@ -0,0 +1,124 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoOwnership the platform has no uid and gid for files
+var ErrNoOwnership = errors.New("File ownership is not supported")
+
+// OwnershipMap group the paths of the tree under root by their owner, the
+// keys are "uid:gid" and the paths are relative to root, "." being root
+// itself, eg: to check a build root only holds files owned by root with
+// m["0:0"] being the only key. Symlinks are not followed.
+func OwnershipMap(root string) (map[string][]string, error) {
+	m := make(map[string][]string)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		uid, gid, ok := fileOwner(info)
+		if !ok {
+			return ErrNoOwnership
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		k := strconv.Itoa(uid) + ":" + strconv.Itoa(gid)
+		m[k] = append(m[k], rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ApplyOwnershipMap change the owners of the paths under root to the ones of
+// m, in the format returned by OwnershipMap. Paths already owned right are
+// left alone, so are the ones not in m. Paths leaving root, or reached through
+// a symlinked directory, fail with ErrEscape. The setuid and setgid bits
+// cleared by the kernel on chown are restored. It usually requires root.
+func ApplyOwnershipMap(root string, m map[string][]string) error {
+	for k, paths := range m {
+		uid, gid, err := parseOwner(k)
+		if err != nil {
+			return err
+		}
+		for _, rel := range paths {
+			p, err := ownedPath(root, rel)
+			if err != nil {
+				return err
+			}
+			info, err := os.Lstat(p)
+			if err != nil {
+				return err
+			}
+			if u, g, ok := fileOwner(info); !ok {
+				return ErrNoOwnership
+			} else if u == uid && g == gid {
+				continue
+			}
+			start := time.Now()
+			err = os.Lchown(p, uid, gid)
+			observe("chown", p, start, 0, err)
+			if err != nil {
+				return err
+			}
+			if info.Mode()&(os.ModeSetuid|os.ModeSetgid) != 0 && info.Mode()&os.ModeSymlink == 0 {
+				start = time.Now()
+				err = os.Chmod(p, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+				observe("chmod", p, start, 0, err)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ownedPath join rel to root, refusing rel leaving root or any ancestor of it
+// under root being a symlink
+func ownedPath(root, rel string) (string, error) {
+	rel = filepath.Clean(rel)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &os.PathError{Op: "chown", Path: rel, Err: ErrEscape}
+	}
+	p := root
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		p = filepath.Join(p, part)
+		info, err := os.Lstat(p)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return "", &os.PathError{Op: "chown", Path: rel, Err: ErrEscape}
+		}
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// parseOwner parse "uid:gid"
+func parseOwner(s string) (uid, gid int, err error) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid owner %q, expecting uid:gid", s)
+	}
+	uid, err = strconv.Atoi(s[:i])
+	if err == nil {
+		gid, err = strconv.Atoi(s[i+1:])
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid owner %q, expecting uid:gid", s)
+	}
+	return uid, gid, nil
+}
@@ -0,0 +1,91 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type permOptions struct {
+	ownership bool
+	matcher   PathMatcher
+}
+
+// PermOption configures CopyPermissions
+type PermOption func(*permOptions)
+
+// WithOwnership copy the owner and group too, which usually requires root
+func WithOwnership() PermOption {
+	return func(o *permOptions) {
+		o.ownership = true
+	}
+}
+
+// WithPermMatcher only copy the permissions of the paths, relative to the roots, m matches
+func WithPermMatcher(m PathMatcher) PermOption {
+	return func(o *permOptions) {
+		o.matcher = m
+	}
+}
+
+// CopyPermissions apply the modes of the tree under srcRoot to the same
+// relative paths under dstRoot, eg: to fix a tree copied without its metadata.
+// Paths missing under dstRoot or of another type are skipped, directories with
+// their content, so a symlink under dstRoot is never followed. The modes of
+// symlinks are meaningless, only their ownership is copied.
+func CopyPermissions(srcRoot, dstRoot string, opts ...PermOption) error {
+	var o permOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return filepath.Walk(srcRoot, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcRoot, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstRoot, rel)
+		stat := os.Lstat
+		if rel == "." {
+			stat = os.Stat
+		}
+		dinfo, err := stat(target)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err != nil || dinfo.Mode().Type() != info.Mode().Type() {
+			// never descend through a symlink, it could lead out of dstRoot
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if o.matcher != nil && !o.matcher.Match(rel) {
+			return nil
+		}
+
+		// chown clears the setuid and setgid bits, so it goes first
+		if o.ownership {
+			if uid, gid, ok := fileOwner(info); ok {
+				start := time.Now()
+				err := os.Lchown(target, uid, gid)
+				observe("chown", target, start, 0, err)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		start := time.Now()
+		err = os.Chmod(target, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+		observe("chmod", target, start, 0, err)
+		return err
+	})
+}
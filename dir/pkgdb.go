@@ -0,0 +1,257 @@
+package dir
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrNoPackageManager = errors.New("Neither rpm nor dpkg is available")
+	ErrNotOwned         = errors.New("Not owned by any package")
+)
+
+// OwnedFile a file and the package owning it
+type OwnedFile struct {
+	Path    string
+	Package string
+	// Modified is true when the digest of the file differs from the one recorded in the package database
+	Modified bool
+}
+
+// packageDBs the databases telling which package manager owns the host
+var packageDBs = []struct{ manager, path string }{
+	{"dpkg", "/var/lib/dpkg/status"},
+	{"rpm", "/var/lib/rpm"},
+	{"rpm", "/usr/lib/sysimage/rpm"},
+}
+
+// packageBatch the most paths or packages given to a single command
+const packageBatch = 256
+
+// packageManager return "rpm" or "dpkg", the one whose database exists on the
+// host, eg: not rpm installed on Debian to build packages, or else whichever
+// is found first
+func packageManager() (string, error) {
+	for _, db := range packageDBs {
+		if _, err := os.Stat(db.path); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(db.manager); err == nil {
+			return db.manager, nil
+		}
+	}
+	for _, v := range []string{"rpm", "dpkg"} {
+		if _, err := exec.LookPath(v); err == nil {
+			return v, nil
+		}
+	}
+	return "", ErrNoPackageManager
+}
+
+// OwnerPackage query the rpm or dpkg database for the package owning path
+func OwnerPackage(path string) (string, error) {
+	pm, err := packageManager()
+	if err != nil {
+		return "", err
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	m, err := ownerPackages(pm, []string{path})
+	if err != nil {
+		return "", err
+	}
+	pkg, ok := m[path]
+	if !ok {
+		return "", ErrNotOwned
+	}
+	return pkg, nil
+}
+
+// ownerPackages map the absolute paths to the packages owning them, unowned
+// paths are left out. A file can be owned by several packages, the first one wins.
+func ownerPackages(pm string, paths []string) (map[string]string, error) {
+	want := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		want[p] = struct{}{}
+	}
+
+	m := make(map[string]string)
+	for i := 0; i < len(paths); i += packageBatch {
+		end := i + packageBatch
+		if end > len(paths) {
+			end = len(paths)
+		}
+		batch := paths[i:end]
+		var cmd *exec.Cmd
+		if pm == "rpm" {
+			// every file of the owning packages, the queried ones are picked below
+			cmd = exec.Command("rpm", append([]string{"-qf", "--queryformat", "[%{FILENAMES}\t%{NAME}\n]"}, batch...)...)
+		} else {
+			cmd = exec.Command("dpkg", append([]string{"-S"}, batch...)...)
+		}
+
+		// both exit non-zero when a path is not owned
+		out, err := cmd.Output()
+		if err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				return nil, err
+			}
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			path, pkg, ok := parseOwnerLine(pm, scanner.Text())
+			if !ok {
+				continue
+			}
+			if _, wanted := want[path]; !wanted {
+				continue
+			}
+			if _, found := m[path]; !found {
+				m[path] = pkg
+			}
+		}
+	}
+	return m, nil
+}
+
+// parseOwnerLine parse a line of ownerPackages, "/path\tpkg" (rpm) or
+// "pkg1, pkg2:arch: /path" (dpkg)
+func parseOwnerLine(pm, line string) (path, pkg string, ok bool) {
+	if pm == "rpm" {
+		i := strings.LastIndex(line, "\t")
+		if i < 0 {
+			return "", "", false
+		}
+		return line[:i], line[i+1:], true
+	}
+
+	// "diversion by pkg from: /path" and "diversion by pkg to: /path"
+	if strings.HasPrefix(line, "diversion by ") {
+		return "", "", false
+	}
+	i := strings.Index(line, ": /")
+	if i < 0 {
+		return "", "", false
+	}
+	pkg = strings.SplitN(line[:i], ",", 2)[0]
+	// strip the architecture qualifier
+	return line[i+2:], strings.SplitN(pkg, ":", 2)[0], true
+}
+
+// VerifyOwnedFiles find the owning package of every file under root and
+// verify its digest against the package database. Files not owned by
+// any package are skipped. The package manager is run once per batch of
+// files, not per file.
+func VerifyOwnedFiles(root string) ([]OwnedFile, error) {
+	pm, err := packageManager()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := Ls(root, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range files {
+		fi, err := os.Lstat(f)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, abs)
+	}
+
+	owners, err := ownerPackages(pm, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []OwnedFile
+	var packages []string
+	seen := make(map[string]struct{})
+	for _, p := range paths {
+		pkg, ok := owners[p]
+		if !ok {
+			continue
+		}
+		owned = append(owned, OwnedFile{Path: p, Package: pkg})
+		if _, ok := seen[pkg]; !ok {
+			seen[pkg] = struct{}{}
+			packages = append(packages, pkg)
+		}
+	}
+
+	modified, err := modifiedFiles(pm, packages)
+	if err != nil {
+		return owned, err
+	}
+	for i, v := range owned {
+		_, owned[i].Modified = modified[v.Path]
+	}
+
+	return owned, nil
+}
+
+// modifiedFiles return the files of pkgs whose digest does not match the package database
+func modifiedFiles(pm string, pkgs []string) (map[string]struct{}, error) {
+	m := make(map[string]struct{})
+	for i := 0; i < len(pkgs); i += packageBatch {
+		end := i + packageBatch
+		if end > len(pkgs) {
+			end = len(pkgs)
+		}
+		batch := pkgs[i:end]
+		var cmd *exec.Cmd
+		if pm == "rpm" {
+			cmd = exec.Command("rpm", append([]string{"-V", "--nodeps", "--nomtime"}, batch...)...)
+		} else {
+			cmd = exec.Command("dpkg", append([]string{"--verify"}, batch...)...)
+		}
+
+		// both exit non-zero when verification fails
+		out, err := cmd.Output()
+		if err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				return nil, err
+			}
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			if p, ok := parseVerifyLine(scanner.Text()); ok {
+				m[p] = struct{}{}
+			}
+		}
+	}
+	return m, nil
+}
+
+// parseVerifyLine the path of a verification line whose digest differs,
+// "S.5....T.  c /etc/foo" (rpm) or "??5?????? c /etc/foo" (dpkg), the path
+// may hold spaces
+func parseVerifyLine(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields[0]) < 3 || fields[0][2] != '5' {
+		return "", false
+	}
+	i := strings.Index(line, " /")
+	if i < 0 {
+		return "", false
+	}
+	return line[i+1:], true
+}
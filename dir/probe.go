@@ -0,0 +1,48 @@
+package dir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Features the capabilities of the file system holding a directory, as
+// detected by Probe. Callers can pick a strategy upfront instead of
+// failing at runtime, eg: clone files where Reflink is supported.
+type Features struct {
+	// Reflink files can share their data blocks copy-on-write (FICLONE)
+	Reflink bool
+	// SparseFiles holes in files don't take disk space
+	SparseFiles bool
+	// Xattrs files can have user extended attributes
+	Xattrs bool
+	// CaseSensitive "a" and "A" are different files
+	CaseSensitive bool
+	// MaxNameLen the maximum length of a file name in bytes, 0 if unknown
+	MaxNameLen int
+	// RenameExchange two paths can be swapped atomically (renameat2 RENAME_EXCHANGE)
+	RenameExchange bool
+}
+
+// Probe detect the Features of the file system holding the directory path
+// by performing cheap tests in a temporary directory it removes afterwards.
+// path must be writable.
+func Probe(path string) (Features, error) {
+	var f Features
+
+	tmp, err := ioutil.TempDir(path, ".probe-")
+	if err != nil {
+		return f, err
+	}
+	defer os.RemoveAll(tmp)
+
+	a := filepath.Join(tmp, "probe")
+	if err := ioutil.WriteFile(a, []byte("probe"), 0644); err != nil {
+		return f, err
+	}
+	if _, err := os.Lstat(filepath.Join(tmp, "PROBE")); os.IsNotExist(err) {
+		f.CaseSensitive = true
+	}
+
+	return f, probe(tmp, a, &f)
+}
@@ -0,0 +1,67 @@
+package dir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// probe fill the linux specific Features, testing on the file a in the directory tmp.
+// Unsupported features are not errors, they are left false.
+func probe(tmp, a string, f *Features) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(tmp, &st); err != nil {
+		return err
+	}
+	f.MaxNameLen = int(st.Namelen)
+
+	f.Reflink = probeReflink(a, filepath.Join(tmp, "clone"))
+	f.Xattrs = unix.Setxattr(a, "user.probe", []byte("1"), 0) == nil
+
+	sparse := filepath.Join(tmp, "sparse")
+	if err := probeSparse(sparse); err == nil {
+		var s unix.Stat_t
+		if unix.Stat(sparse, &s) == nil {
+			// Blocks is in 512 bytes units whatever the block size is
+			f.SparseFiles = s.Blocks*512 < s.Size
+		}
+	}
+
+	b := filepath.Join(tmp, "exchange")
+	if err := ioutil.WriteFile(b, nil, 0644); err != nil {
+		return err
+	}
+	f.RenameExchange = unix.Renameat2(unix.AT_FDCWD, a, unix.AT_FDCWD, b, unix.RENAME_EXCHANGE) == nil
+
+	return nil
+}
+
+func probeReflink(src, dst string) bool {
+	s, err := os.Open(src)
+	if err != nil {
+		return false
+	}
+	defer s.Close()
+	d, err := os.Create(dst)
+	if err != nil {
+		return false
+	}
+	defer d.Close()
+	return unix.IoctlFileClone(int(d.Fd()), int(s.Fd())) == nil
+}
+
+// probeSparse write a single byte 1MiB into a new file
+func probeSparse(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteAt([]byte{1}, 1<<20)
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
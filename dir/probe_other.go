@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package dir
+
+// probe only CaseSensitive is detected outside linux
+func probe(tmp, a string, f *Features) error {
+	return nil
+}
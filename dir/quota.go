@@ -0,0 +1,74 @@
+package dir
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// EvictionStrategy decides which files EnforceQuota removes first
+type EvictionStrategy int
+
+const (
+	// EvictLRU remove the least recently accessed files first. Mind that
+	// file systems mounted with noatime don't update access times.
+	EvictLRU EvictionStrategy = iota
+	// EvictOldest remove the least recently modified files first
+	EvictOldest
+	// EvictLargest remove the largest files first
+	EvictLargest
+)
+
+// EnforceQuota remove regular files under root, in the order of strategy,
+// until the total size of the ones left is at most maxBytes, eg: to keep a
+// download cache under a budget. Directories and symlinks are kept.
+func EnforceQuota(root string, maxBytes int64, strategy EvictionStrategy) error {
+	type file struct {
+		path string
+		size int64
+		time time.Time
+	}
+
+	var (
+		files []file
+		total int64
+	)
+	err := Walk(root, func(p string, info os.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f := file{p, info.Size(), info.ModTime()}
+		if strategy == EvictLRU {
+			if t, err := GetTimes(p); err == nil {
+				f.time = t.Access
+			}
+		}
+		files = append(files, f)
+		total += f.size
+		return nil
+	})
+	if err != nil || total <= maxBytes {
+		return err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if strategy == EvictLargest {
+			return files[i].size > files[j].size
+		}
+		return files[i].time.Before(files[j].time)
+	})
+
+	for _, f := range files {
+		if total <= maxBytes {
+			break
+		}
+		start := time.Now()
+		err := os.Remove(f.path)
+		observe("remove", f.path, start, 0, err)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
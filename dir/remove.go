@@ -0,0 +1,134 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrProtected the path is a file system root, a system directory or the home directory
+var ErrProtected = errors.New("Refusing to remove a protected path")
+
+// protected the system directories SafeRemove refuses to remove, besides file system roots and $HOME
+var protected = []string{"/bin", "/boot", "/dev", "/etc", "/home", "/lib", "/lib64", "/opt", "/proc", "/root", "/sbin", "/srv", "/sys", "/usr", "/var"}
+
+type removeOptions struct {
+	force   bool
+	dryRun  bool
+	sandbox string
+}
+
+// RemoveOption configures SafeRemove
+type RemoveOption func(*removeOptions)
+
+// WithRemoveForce allow removing the protected paths
+func WithRemoveForce() RemoveOption {
+	return func(o *removeOptions) {
+		o.force = true
+	}
+}
+
+// WithRemoveDryRun remove nothing, only list what would be removed
+func WithRemoveDryRun() RemoveOption {
+	return func(o *removeOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithSandbox refuse with ErrEscape to remove paths not under root, symlinks resolved
+func WithSandbox(root string) RemoveOption {
+	return func(o *removeOptions) {
+		o.sandbox = root
+	}
+}
+
+// SafeRemove remove path and its content like os.RemoveAll, returning the removed
+// paths deepest first, like "rm -rv". It refuses with ErrProtected to remove
+// file system roots, the system directories, $HOME and their parents unless
+// forced, wherever symlinks in the parents of path lead. Symlinks are removed, not followed.
+func SafeRemove(path string, opts ...RemoveOption) ([]string, error) {
+	var o removeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p, err := realPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !o.force {
+		guarded := protected
+		if home, _ := os.UserHomeDir(); len(home) > 0 {
+			guarded = append([]string{realOrClean(home)}, protected...)
+		}
+		if filepath.Dir(p) == p {
+			return nil, fmt.Errorf("remove %s: %w", path, ErrProtected)
+		}
+		// the parents of the protected paths would take them along
+		for _, v := range guarded {
+			if within(p, v) {
+				return nil, fmt.Errorf("remove %s: %w", path, ErrProtected)
+			}
+		}
+	}
+
+	if len(o.sandbox) > 0 {
+		root, err := realPath(o.sandbox)
+		if err != nil {
+			return nil, err
+		}
+		if p == root || !within(root, p) {
+			return nil, fmt.Errorf("remove %s: %w %s", path, ErrEscape, o.sandbox)
+		}
+	}
+
+	var paths []string
+	err = Walk(path, func(p string, info os.FileInfo) error {
+		paths = append(paths, p)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the reversed lexical order lists the content of directories before them
+	for i, j := 0, len(paths)-1; i < j; i, j = i+1, j-1 {
+		paths[i], paths[j] = paths[j], paths[i]
+	}
+
+	if o.dryRun {
+		return paths, nil
+	}
+	start := time.Now()
+	err = os.RemoveAll(path)
+	observe("remove", path, start, 0, err)
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// realPath the absolute path with the symlinks in its parents resolved, the last component kept
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Dir(abs) == abs {
+		return abs, nil
+	}
+	return filepath.Join(realOrClean(filepath.Dir(abs)), filepath.Base(abs)), nil
+}
+
+// realOrClean p with its symlinks resolved, or just cleaned if it doesn't exist
+func realOrClean(p string) string {
+	if r, err := filepath.EvalSymlinks(p); err == nil {
+		return r
+	}
+	return filepath.Clean(p)
+}
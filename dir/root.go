@@ -0,0 +1,217 @@
+package dir
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrEscape a path resolves outside of its Root
+var ErrEscape = errors.New("Path escapes the root")
+
+// Root a directory acting as a jail: the names given to its methods are
+// relative to it, and neither "..", absolute symlinks nor symlinks pointing
+// outside can make them resolve out of it, which is ErrEscape. On Linux 5.6+
+// paths are resolved by the kernel with openat2(RESOLVE_BENEATH), which is
+// race free. Elsewhere, or where seccomp denies openat2, they are checked
+// before use, which a concurrent rename of a directory into a symlink could
+// still defeat.
+type Root struct {
+	path string
+	real string
+	f    *os.File
+}
+
+// NewRoot open the directory path as a Root
+func NewRoot(path string) (*Root, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(real)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || !info.IsDir() {
+		f.Close()
+		if err == nil {
+			err = &os.PathError{Op: "open", Path: path, Err: errors.New("not a directory")}
+		}
+		return nil, err
+	}
+	return &Root{path: path, real: real, f: f}, nil
+}
+
+// Path the directory the Root was opened with
+func (r *Root) Path() string {
+	return r.path
+}
+
+// Close release the directory
+func (r *Root) Close() error {
+	return r.f.Close()
+}
+
+// Open open the file name for reading
+func (r *Root) Open(name string) (*os.File, error) {
+	return r.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile open the file name like os.OpenFile does
+func (r *Root) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return r.openFile(name, flag, perm)
+}
+
+// Stat describe the file name, following symlinks inside the root. The file
+// is not opened, so FIFOs don't block and unreadable files can be described.
+func (r *Root) Stat(name string) (os.FileInfo, error) {
+	return r.stat(name)
+}
+
+// Ls list the entries of the directory name, sorted, as paths relative to the root
+func (r *Root) Ls(name string) ([]string, error) {
+	// opening a FIFO for reading blocks until a writer comes
+	info, err := r.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	for i, n := range names {
+		names[i] = filepath.Join(name, n)
+	}
+	return names, nil
+}
+
+// Mkdir create the directory name with mode perm
+func (r *Root) Mkdir(name string, perm os.FileMode) error {
+	start := time.Now()
+	err := r.mkdir(name, perm)
+	observe("mkdir", filepath.Join(r.path, name), start, 0, err)
+	return err
+}
+
+// Remove remove the file or empty directory name. A symlink is removed itself,
+// not what it points to.
+func (r *Root) Remove(name string) error {
+	start := time.Now()
+	err := r.remove(name)
+	observe("remove", filepath.Join(r.path, name), start, 0, err)
+	return err
+}
+
+// Copy copy the file src to dst, both inside the root, with the permissions of src
+func (r *Root) Copy(src, dst string) error {
+	start := time.Now()
+	in, err := r.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := r.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		observe("copy", filepath.Join(r.path, dst), start, 0, err)
+		return err
+	}
+	n, err := io.Copy(out, in)
+	if err1 := out.Close(); err == nil {
+		err = err1
+	}
+	observe("copy", filepath.Join(r.path, dst), start, n, err)
+	return err
+}
+
+// resolve return the real path of name, following symlinks, if it is inside
+// the root. With parentOnly the last component of name is not followed,
+// for operations acting on links themselves.
+func (r *Root) resolve(op, name string, parentOnly bool) (string, error) {
+	escape := &os.PathError{Op: op, Path: name, Err: ErrEscape}
+	if filepath.IsAbs(name) {
+		return "", escape
+	}
+
+	p := filepath.Join(r.real, name)
+	if !within(r.real, p) {
+		return "", escape
+	}
+
+	dir, base := p, ""
+	if parentOnly || !exists(p) {
+		dir, base = filepath.Dir(p), filepath.Base(p)
+		if p == r.real {
+			return "", escape
+		}
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	if !within(r.real, real) {
+		return "", escape
+	}
+	return filepath.Join(real, base), nil
+}
+
+// within whether the cleaned path p is root or below it
+func within(root, p string) bool {
+	return p == root || strings.HasPrefix(p, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+func exists(p string) bool {
+	_, err := os.Lstat(p)
+	return err == nil
+}
+
+// the checked implementation used where openat2 is not available
+
+func (r *Root) openFileChecked(name string, flag int, perm os.FileMode) (*os.File, error) {
+	p, err := r.resolve("open", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p, flag, perm)
+}
+
+func (r *Root) statChecked(name string) (os.FileInfo, error) {
+	p, err := r.resolve("stat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(p)
+}
+
+func (r *Root) mkdirChecked(name string, perm os.FileMode) error {
+	p, err := r.resolve("mkdir", name, true)
+	if err != nil {
+		return err
+	}
+	return os.Mkdir(p, perm)
+}
+
+func (r *Root) removeChecked(name string) error {
+	p, err := r.resolve("remove", name, true)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
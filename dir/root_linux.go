@@ -0,0 +1,116 @@
+package dir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// openat2 open name beneath the root, nil and no error if the kernel doesn't
+// support openat2 or a seccomp filter, like the ones of container runtimes,
+// denies it with EPERM. A genuine EPERM is returned again by the fallback.
+func (r *Root) openat2(op, name string, flag int, perm os.FileMode) (*os.File, error) {
+	how := &unix.OpenHow{
+		Flags:   uint64(flag) | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_BENEATH | unix.RESOLVE_NO_MAGICLINKS,
+	}
+	// a mode without O_CREAT is EINVAL
+	if flag&os.O_CREATE != 0 {
+		how.Mode = uint64(syscallMode(perm))
+	}
+	fd, err := unix.Openat2(int(r.f.Fd()), name, how)
+	switch {
+	case errors.Is(err, unix.ENOSYS), errors.Is(err, unix.EPERM):
+		return nil, nil
+	case errors.Is(err, unix.EXDEV):
+		return nil, &os.PathError{Op: op, Path: name, Err: ErrEscape}
+	case err != nil:
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(fd), filepath.Join(r.path, name)), nil
+}
+
+func (r *Root) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	f, err := r.openat2("open", name, flag, perm)
+	if f == nil && err == nil {
+		return r.openFileChecked(name, flag, perm)
+	}
+	return f, err
+}
+
+// stat describe name through an O_PATH descriptor, which opens neither FIFOs
+// nor devices and needs no read permission
+func (r *Root) stat(name string) (os.FileInfo, error) {
+	f, err := r.openat2("stat", name, unix.O_PATH, 0)
+	if f == nil && err == nil {
+		return r.statChecked(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// parent open the directory holding name beneath the root, nil and no error
+// if openat2 is not available
+func (r *Root) parent(op, name string) (*os.File, string, error) {
+	name = filepath.Clean(name)
+	base := filepath.Base(name)
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: ErrEscape}
+	}
+	f, err := r.openat2(op, filepath.Dir(name), unix.O_PATH|unix.O_DIRECTORY, 0)
+	return f, base, err
+}
+
+func (r *Root) mkdir(name string, perm os.FileMode) error {
+	d, base, err := r.parent("mkdir", name)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return r.mkdirChecked(name, perm)
+	}
+	defer d.Close()
+	if err := unix.Mkdirat(int(d.Fd()), base, syscallMode(perm)); err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (r *Root) remove(name string) error {
+	d, base, err := r.parent("remove", name)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return r.removeChecked(name)
+	}
+	defer d.Close()
+	err = unix.Unlinkat(int(d.Fd()), base, 0)
+	if errors.Is(err, unix.EISDIR) {
+		err = unix.Unlinkat(int(d.Fd()), base, unix.AT_REMOVEDIR)
+	}
+	if err != nil {
+		return &os.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return nil
+}
+
+// syscallMode the permission bits of mode for the system calls
+func syscallMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= unix.S_ISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= unix.S_ISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= unix.S_ISVTX
+	}
+	return m
+}
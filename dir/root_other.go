@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package dir
+
+import "os"
+
+func (r *Root) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return r.openFileChecked(name, flag, perm)
+}
+
+func (r *Root) stat(name string) (os.FileInfo, error) {
+	return r.statChecked(name)
+}
+
+func (r *Root) mkdir(name string, perm os.FileMode) error {
+	return r.mkdirChecked(name, perm)
+}
+
+func (r *Root) remove(name string) error {
+	return r.removeChecked(name)
+}
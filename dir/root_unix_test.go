@@ -0,0 +1,33 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestRootFifo(t *testing.T) {
+	d := t.TempDir()
+	if err := syscall.Mkfifo(filepath.Join(d, "fifo"), 0600); err != nil {
+		t.Skipf("[dir]: Root test skipped, can't create a fifo: %s", err)
+	}
+
+	r, err := NewRoot(d)
+	if err != nil {
+		t.Fatalf("[dir]: NewRoot test failed with %s", err)
+	}
+	defer r.Close()
+
+	// both would block forever opening the fifo for reading
+	info, err := r.Stat("fifo")
+	if err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("[dir]: Root.Stat test failed, expecting a named pipe, got %v, err %v", info, err)
+	}
+	if names, err := r.Ls("fifo"); err == nil {
+		t.Errorf("[dir]: Root.Ls test failed, expecting an error for a fifo, got %v", names)
+	}
+}
@@ -0,0 +1,61 @@
+package dir
+
+import "os"
+
+type sizeOptions struct {
+	disk bool
+}
+
+// SizeOption configures Size
+type SizeOption func(*sizeOptions)
+
+// WithDiskUsage report the space allocated on disk, in blocks, instead of the
+// apparent sizes, like du does by default: sparse files count less and small
+// files count a whole block. Where the platform doesn't tell, it is the
+// apparent size.
+func WithDiskUsage() SizeOption {
+	return func(o *sizeOptions) {
+		o.disk = true
+	}
+}
+
+// Size the total size in bytes and the number of the regular files under
+// path, path included, following the symlinks or not. Like du, a file reached
+// through several hard links or followed symlinks is counted once.
+func Size(path string, followSymlinks bool, opts ...SizeOption) (size int64, files int, err error) {
+	var o sizeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var wopts []WalkOption
+	if followSymlinks {
+		wopts = append(wopts, WithFollowSymlinks())
+	}
+
+	type id struct{ dev, ino uint64 }
+	seen := make(map[id]struct{})
+
+	err = Walk(path, func(p string, info os.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if dev, ino, ok := fileID(info); ok {
+			if _, dup := seen[id{dev, ino}]; dup {
+				return nil
+			}
+			seen[id{dev, ino}] = struct{}{}
+		}
+		files++
+		if o.disk {
+			if n, ok := diskUsage(info); ok {
+				size += n
+				return nil
+			}
+		}
+		size += info.Size()
+		return nil
+	}, wopts...)
+
+	return size, files, err
+}
@@ -0,0 +1,16 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package dir
+
+import "os"
+
+// diskUsage not known here
+func diskUsage(info os.FileInfo) (int64, bool) {
+	return 0, false
+}
+
+// fileID not known here, every path is its own file
+func fileID(info os.FileInfo) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
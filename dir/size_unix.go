@@ -0,0 +1,28 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package dir
+
+import (
+	"os"
+	"syscall"
+)
+
+// diskUsage the bytes allocated for the file info describes
+func diskUsage(info os.FileInfo) (int64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	// st_blocks is in 512 byte units whatever the block size
+	return int64(st.Blocks) * 512, true
+}
+
+// fileID the device and inode numbers identifying the file info describes
+func fileID(info os.FileInfo) (dev, ino uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint64(st.Dev), uint64(st.Ino), true
+}
@@ -0,0 +1,103 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stats aggregate counters of a tree, see WalkStats
+type Stats struct {
+	Files     int
+	Dirs      int
+	Symlinks  int
+	TotalSize int64
+	// LargestFile the path of the largest regular file
+	LargestFile string
+	// DeepestPath the path with the most components below root
+	DeepestPath string
+}
+
+type statsOptions struct {
+	matcher    PathMatcher
+	skipErrors bool
+}
+
+// StatsOption configures WalkStats
+type StatsOption func(*statsOptions)
+
+// WithStatsMatcher only count the paths, relative to root, m matches
+func WithStatsMatcher(m PathMatcher) StatsOption {
+	return func(o *statsOptions) {
+		o.matcher = m
+	}
+}
+
+// WithStatsSkipErrors skip the paths that can't be read, eg: for permissions, instead of failing
+func WithStatsSkipErrors() StatsOption {
+	return func(o *statsOptions) {
+		o.skipErrors = true
+	}
+}
+
+// WalkStats count the files, directories and symlinks under root, root
+// excluded, and the total size of the regular files in a single walk.
+// Symlinks are not followed.
+func WalkStats(root string, opts ...StatsOption) (Stats, error) {
+	var o statsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var s Stats
+	var largest int64 = -1
+	deepest := -1
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if o.skipErrors {
+				if info != nil && info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			return err
+		}
+		if p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if o.matcher != nil && !o.matcher.Match(rel) {
+			if info.IsDir() && !descend(o.matcher, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case info.IsDir():
+			s.Dirs++
+		case info.Mode()&os.ModeSymlink != 0:
+			s.Symlinks++
+		case info.Mode().IsRegular():
+			s.Files++
+			s.TotalSize += info.Size()
+			if info.Size() > largest {
+				largest = info.Size()
+				s.LargestFile = p
+			}
+		}
+
+		if depth := strings.Count(rel, string(filepath.Separator)); depth > deepest {
+			deepest = depth
+			s.DeepestPath = p
+		}
+		return nil
+	})
+
+	return s, err
+}
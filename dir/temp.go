@@ -0,0 +1,124 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrInsecureTempDir the per-user parent of secure temporary directories is
+// not a directory of the current user with 0700 permissions
+var ErrInsecureTempDir = errors.New("Temporary directory parent is not private")
+
+var temps = struct {
+	sync.Mutex
+	dirs map[string]struct{}
+}{dirs: make(map[string]struct{})}
+
+// TempDir create a new directory named after namespace in the system temporary
+// directory, eg: "/tmp/namespace-123456", with 0700 permissions. cleanup
+// removes it with its content, CleanupTempDirs removes the ones not cleaned up yet.
+func TempDir(namespace string) (path string, cleanup func() error, err error) {
+	start := time.Now()
+	path, err = ioutil.TempDir("", namespace+"-")
+	observe("mkdir", path, start, 0, err)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, register(path), nil
+}
+
+// SecureTempDir like TempDir, but the directory is created in a parent only
+// the current user can access, eg: "/tmp/namespace-1000/123456". On shared
+// systems this defeats other users pre-creating or racing on the parent,
+// which is refused with ErrInsecureTempDir if it exists and is not private.
+func SecureTempDir(namespace string) (path string, cleanup func() error, err error) {
+	parent := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", namespace, os.Getuid()))
+	start := time.Now()
+	err = os.Mkdir(parent, 0700)
+	if !os.IsExist(err) {
+		observe("mkdir", parent, start, 0, err)
+	}
+	if err != nil && !os.IsExist(err) {
+		return "", nil, err
+	}
+
+	// Lstat, a symlink planted by someone else is refused too
+	info, err := os.Lstat(parent)
+	if err != nil {
+		return "", nil, err
+	}
+	if !info.IsDir() || info.Mode().Perm() != 0700 {
+		return "", nil, fmt.Errorf("%s: %w", parent, ErrInsecureTempDir)
+	}
+	if uid, _, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return "", nil, fmt.Errorf("%s: %w", parent, ErrInsecureTempDir)
+	}
+
+	start = time.Now()
+	path, err = ioutil.TempDir(parent, "")
+	observe("mkdir", path, start, 0, err)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, register(path), nil
+}
+
+// WithTempDir call fn with a new TempDir, removed when fn returns, even if it panics
+func WithTempDir(namespace string, fn func(dir string) error) (err error) {
+	path, cleanup, err := TempDir(namespace)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := cleanup(); err == nil {
+			err = err1
+		}
+	}()
+	return fn(path)
+}
+
+// CleanupTempDirs remove the directories TempDir and SecureTempDir created
+// that were not cleaned up yet, eg: from a signal handler before exiting
+func CleanupTempDirs() error {
+	temps.Lock()
+	dirs := make([]string, 0, len(temps.dirs))
+	for p := range temps.dirs {
+		dirs = append(dirs, p)
+	}
+	temps.Unlock()
+
+	var first error
+	for _, p := range dirs {
+		if err := removeTemp(p); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// register track path and return its cleanup
+func register(path string) func() error {
+	temps.Lock()
+	temps.dirs[path] = struct{}{}
+	temps.Unlock()
+	return func() error {
+		return removeTemp(path)
+	}
+}
+
+func removeTemp(path string) error {
+	start := time.Now()
+	err := os.RemoveAll(path)
+	observe("remove", path, start, 0, err)
+	if err == nil {
+		temps.Lock()
+		delete(temps.dirs, path)
+		temps.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package dir
+
+import "time"
+
+// Times the timestamps of a file. Birth is the creation time, only known
+// where HasBirth is true: Linux file systems reporting it through statx.
+// Change is zero where the platform doesn't report it.
+type Times struct {
+	Access   time.Time
+	Modify   time.Time
+	Change   time.Time
+	Birth    time.Time
+	HasBirth bool
+}
+
+// GetTimes the timestamps of path, following symlinks, with nanosecond precision
+func GetTimes(path string) (Times, error) {
+	return getTimes(path)
+}
+
+// SetTimes set the access and modification times of path, following
+// symlinks, with nanosecond precision. The change time is always set to
+// the current time by the system, and the birth time can't be changed.
+func SetTimes(path string, atime, mtime time.Time) error {
+	return setTimes(path, atime, mtime)
+}
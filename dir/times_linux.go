@@ -0,0 +1,54 @@
+package dir
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func getTimes(path string) (Times, error) {
+	var st unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_ATIME|unix.STATX_MTIME|unix.STATX_CTIME|unix.STATX_BTIME, &st)
+	if err == unix.ENOSYS {
+		// kernels older than 4.11
+		return getTimesFallback(path)
+	}
+	if err != nil {
+		return Times{}, &os.PathError{Op: "statx", Path: path, Err: err}
+	}
+
+	ts := func(t unix.StatxTimestamp) time.Time {
+		return time.Unix(t.Sec, int64(t.Nsec))
+	}
+	t := Times{
+		Access: ts(st.Atime),
+		Modify: ts(st.Mtime),
+		Change: ts(st.Ctime),
+	}
+	if st.Mask&unix.STATX_BTIME != 0 {
+		t.Birth = ts(st.Btime)
+		t.HasBirth = true
+	}
+	return t, nil
+}
+
+func getTimesFallback(path string) (Times, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return Times{}, &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+	return Times{
+		Access: time.Unix(st.Atim.Unix()),
+		Modify: time.Unix(st.Mtim.Unix()),
+		Change: time.Unix(st.Ctim.Unix()),
+	}, nil
+}
+
+func setTimes(path string, atime, mtime time.Time) error {
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(mtime.UnixNano())}
+	if err := unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, 0); err != nil {
+		return &os.PathError{Op: "utimensat", Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package dir
+
+import (
+	"os"
+	"time"
+)
+
+// getTimes only the modification time is portable, it is used as access time too
+func getTimes(path string) (Times, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Times{}, err
+	}
+	return Times{Access: info.ModTime(), Modify: info.ModTime()}, nil
+}
+
+func setTimes(path string, atime, mtime time.Time) error {
+	return os.Chtimes(path, atime, mtime)
+}
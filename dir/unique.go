@@ -0,0 +1,77 @@
+package dir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UniqueScheme build the n-th (starting from 1) alternative name for base + ext
+type UniqueScheme func(base, ext string, n int) string
+
+// NumberedScheme "file (1).txt", "file (2).txt"...
+func NumberedScheme(base, ext string, n int) string {
+	return fmt.Sprintf("%s (%d)%s", base, n, ext)
+}
+
+// TimestampScheme "file-20060102150405.txt", then "file-20060102150405-2.txt"...
+func TimestampScheme(base, ext string, n int) string {
+	ts := time.Now().Format("20060102150405")
+	if n > 1 {
+		return fmt.Sprintf("%s-%s-%d%s", base, ts, n, ext)
+	}
+	return fmt.Sprintf("%s-%s%s", base, ts, ext)
+}
+
+// UniquePath return path unchanged if nothing exists there, otherwise the first
+// free alternative built by scheme, NumberedScheme by default. The extension is
+// kept at the end, including compound ones like ".tar.gz", the leading dot of
+// hidden files is not one: ".bashrc" becomes ".bashrc (1)".
+// Note the path is only free at the time of the check.
+func UniquePath(path string, scheme ...UniqueScheme) (string, error) {
+	fn := NumberedScheme
+	if len(scheme) > 0 {
+		fn = scheme[0]
+	}
+
+	free := func(p string) (bool, error) {
+		_, err := os.Lstat(p)
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	ok, err := free(path)
+	if ok || err != nil {
+		return path, err
+	}
+
+	d, name := filepath.Split(path)
+	base, ext := splitExt(name)
+
+	for n := 1; n < 10000; n++ {
+		p := filepath.Join(d, fn(base, ext, n))
+		ok, err := free(p)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no unique path found for %s", path)
+}
+
+// splitExt split name into its base and extension, compound ones like ".tar.gz"
+// included. Leading dots belong to the base, ".bashrc" has no extension.
+func splitExt(name string) (base, ext string) {
+	rest := strings.TrimLeft(name, ".")
+	ext = filepath.Ext(rest)
+	if strings.HasSuffix(strings.TrimSuffix(rest, ext), ".tar") {
+		ext = ".tar" + ext
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
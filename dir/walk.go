@@ -0,0 +1,295 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SkipDir returned by a WalkFunc skips the directory it was called for,
+// or the rest of the parent directory when it was called for a file.
+var SkipDir = filepath.SkipDir
+
+// WalkFunc is called by Walk for every path visited, root included
+type WalkFunc func(path string, info os.FileInfo) error
+
+type walkOptions struct {
+	maxDepth int
+	follow   bool
+	onError  func(path string, err error) error
+	matcher  PathMatcher
+	workers  int
+}
+
+// WalkOption configures Walk
+type WalkOption func(*walkOptions)
+
+// WithMaxDepth do not descend more than n levels below root, 1 meaning the entries of root only
+func WithMaxDepth(n int) WalkOption {
+	return func(o *walkOptions) {
+		o.maxDepth = n
+	}
+}
+
+// WithFollowSymlinks descend into the directories symlinks point to, the
+// WalkFunc receiving the info of the target. A symlink to one of its own
+// parents is not followed, to avoid loops.
+func WithFollowSymlinks() WalkOption {
+	return func(o *walkOptions) {
+		o.follow = true
+	}
+}
+
+// WithErrorHandler call fn with the paths that can't be read instead of
+// stopping. The walk goes on if fn returns nil and stops with its error otherwise.
+func WithErrorHandler(fn func(path string, err error) error) WalkOption {
+	return func(o *walkOptions) {
+		o.onError = fn
+	}
+}
+
+// WithWalkMatcher only call the WalkFunc for the paths, relative to root, m
+// matches. If m has a Descend method, like GlobMatcher, directories it
+// rejects are not read at all.
+func WithWalkMatcher(m PathMatcher) WalkOption {
+	return func(o *walkOptions) {
+		o.matcher = m
+	}
+}
+
+// WithWorkers read directories ahead with n goroutines, which pays off for
+// trees of hundreds of thousands of files, especially on network file systems.
+// fn is still called from the calling goroutine in lexical order, so the
+// output of the walk is the same as without workers.
+func WithWorkers(n int) WalkOption {
+	return func(o *walkOptions) {
+		o.workers = n
+	}
+}
+
+// Walk call fn for root and every path under it in lexical order, like
+// filepath.Walk, with depth limits, symlink following and error handling
+// configured by opts. Walking stops at the first error, fn's or the
+// reading of a directory, unless WithErrorHandler says otherwise.
+func Walk(root string, fn WalkFunc, opts ...WalkOption) error {
+	o := walkOptions{maxDepth: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.onError == nil {
+		o.onError = func(_ string, err error) error { return err }
+	}
+
+	w := &walker{root: root, fn: fn, o: o}
+	if o.workers > 1 {
+		w.pending = make(map[string]*listing)
+		w.cond = sync.NewCond(&w.mu)
+		defer w.stopWorkers()
+		w.startWorkers()
+	}
+
+	info, err := w.stat(root)
+	if err != nil {
+		return o.onError(root, err)
+	}
+	err = w.walk(root, info, 0, nil)
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	root string
+	fn   WalkFunc
+	o    walkOptions
+
+	// the directories read ahead by the workers, see WithWorkers
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending map[string]*listing
+	backlog []*listing
+	stopped bool
+}
+
+// listing the entries of a directory with their infos
+type listing struct {
+	path  string
+	taken bool
+	ready chan struct{}
+	names []string
+	infos []os.FileInfo
+	errs  []error
+	err   error
+}
+
+// stat the path, following it if it is a symlink and symlinks are followed
+func (w *walker) stat(p string) (os.FileInfo, error) {
+	info, err := os.Lstat(p)
+	if err != nil || !w.o.follow || info.Mode()&os.ModeSymlink == 0 {
+		return info, err
+	}
+	if target, err := os.Stat(p); err == nil {
+		return target, nil
+	}
+	// a dangling symlink is reported as a symlink
+	return info, nil
+}
+
+// walk visit p and, if it is a directory, its entries. parents holds the real
+// paths of the directories above p when following symlinks.
+func (w *walker) walk(p string, info os.FileInfo, depth int, parents []string) error {
+	rel, err := filepath.Rel(w.root, p)
+	if err != nil {
+		return err
+	}
+
+	if w.o.matcher == nil || rel == "." || w.o.matcher.Match(rel) {
+		if err := w.fn(p, info); err != nil {
+			return err
+		}
+	}
+
+	if !info.IsDir() || depth == w.o.maxDepth {
+		return nil
+	}
+	if w.o.matcher != nil && rel != "." && !descend(w.o.matcher, rel) {
+		return nil
+	}
+
+	if w.o.follow {
+		real, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			return w.o.onError(p, err)
+		}
+		for _, v := range parents {
+			if v == real {
+				return nil
+			}
+		}
+		parents = append(parents, real)
+	}
+
+	l := w.list(p)
+	if l.err != nil {
+		if err := w.o.onError(p, l.err); err != nil {
+			return err
+		}
+	}
+
+	if w.pending != nil && depth+1 != w.o.maxDepth {
+		for i, cinfo := range l.infos {
+			if cinfo != nil && cinfo.IsDir() && w.descend(filepath.Join(p, l.names[i])) {
+				w.prefetch(filepath.Join(p, l.names[i]))
+			}
+		}
+	}
+
+	for i, name := range l.names {
+		child := filepath.Join(p, name)
+		if l.errs[i] != nil {
+			if err := w.o.onError(child, l.errs[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		cinfo := l.infos[i]
+		err := w.walk(child, cinfo, depth+1, parents)
+		if err == SkipDir {
+			if cinfo.IsDir() {
+				continue
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// descend whether the directory p would be read by walk
+func (w *walker) descend(p string) bool {
+	if w.o.matcher == nil {
+		return true
+	}
+	rel, err := filepath.Rel(w.root, p)
+	return err == nil && descend(w.o.matcher, rel)
+}
+
+// read list the directory p
+func (w *walker) read(l *listing) {
+	l.names, l.err = readDirNames(l.path)
+	l.infos = make([]os.FileInfo, len(l.names))
+	l.errs = make([]error, len(l.names))
+	for i, name := range l.names {
+		l.infos[i], l.errs[i] = w.stat(filepath.Join(l.path, name))
+	}
+}
+
+// list the directory p, waiting for the workers if one is reading it
+func (w *walker) list(p string) *listing {
+	if w.pending != nil {
+		w.mu.Lock()
+		l, ok := w.pending[p]
+		delete(w.pending, p)
+		taken := ok && l.taken
+		if ok {
+			// not read yet, the workers will skip it
+			l.taken = true
+		}
+		w.mu.Unlock()
+		if taken {
+			<-l.ready
+			return l
+		}
+	}
+	l := &listing{path: p}
+	w.read(l)
+	return l
+}
+
+// prefetch queue the directory p to be read ahead by the workers
+func (w *walker) prefetch(p string) {
+	l := &listing{path: p, ready: make(chan struct{})}
+	w.mu.Lock()
+	w.pending[p] = l
+	w.backlog = append(w.backlog, l)
+	w.mu.Unlock()
+	w.cond.Signal()
+}
+
+func (w *walker) startWorkers() {
+	for i := 0; i < w.o.workers; i++ {
+		go func() {
+			for {
+				w.mu.Lock()
+				for len(w.backlog) == 0 && !w.stopped {
+					w.cond.Wait()
+				}
+				if w.stopped {
+					w.mu.Unlock()
+					return
+				}
+				l := w.backlog[0]
+				w.backlog = w.backlog[1:]
+				taken := l.taken
+				l.taken = true
+				w.mu.Unlock()
+
+				if !taken {
+					w.read(l)
+					close(l.ready)
+				}
+			}
+		}()
+	}
+}
+
+func (w *walker) stopWorkers() {
+	w.mu.Lock()
+	w.stopped = true
+	w.backlog = nil
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
@@ -0,0 +1,238 @@
+package dir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrWatchUnsupported = errors.New("Watching is not supported on this platform")
+
+// EventMask the kinds of file system events
+type EventMask uint32
+
+const (
+	Create EventMask = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+
+	AllEvents = Create | Write | Remove | Rename | Chmod
+)
+
+func (m EventMask) String() string {
+	var s []string
+	for i, v := range []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"} {
+		if m&(1<<uint(i)) != 0 {
+			s = append(s, v)
+		}
+	}
+	return strings.Join(s, "|")
+}
+
+// Event a file system event. OldPath is set on the Create event of a path
+// renamed within the watched tree, to the path it had before.
+type Event struct {
+	Path    string
+	Op      EventMask
+	OldPath string
+}
+
+// WatchGlob watch the paths matching the glob pattern (see GlobMatcher) for events.
+// Only the directories that can contain matching paths are watched, watches are
+// added and removed as such directories appear and disappear.
+func WatchGlob(pattern string, events EventMask) (*Watcher, error) {
+	m, err := NewGlobMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return WatchMatcher(m.Root(), m, events)
+}
+
+// WatchMatcher watch the tree under root for events on paths accepted by m.
+// If m has a "Descend(dir string) bool" method, like GlobMatcher, directories
+// it rejects are not watched at all.
+func WatchMatcher(root string, m PathMatcher, events EventMask) (*Watcher, error) {
+	return newWatcher(root, m, events)
+}
+
+// descend whether the directory dir needs to be watched for m
+func descend(m PathMatcher, dir string) bool {
+	if d, ok := m.(interface{ Descend(string) bool }); ok {
+		return d.Descend(dir)
+	}
+	return true
+}
+
+type watchOptions struct {
+	debounce time.Duration
+	onError  func(error)
+}
+
+// WatchOption configures Watch
+type WatchOption func(*watchOptions)
+
+// WithDebounce deliver the events once no new one arrived for d, merging the
+// events of the same path, eg: to rebuild once after a burst of writes
+func WithDebounce(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = d
+	}
+}
+
+// WithWatchErrors call fn with the errors of the watcher, like ErrEventOverflow,
+// they are dropped otherwise
+func WithWatchErrors(fn func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = fn
+	}
+}
+
+// matchAll a PathMatcher matching every path
+type matchAll struct{}
+
+func (matchAll) Match(string) bool { return true }
+
+// matchPath a PathMatcher matching a single path
+type matchPath string
+
+func (m matchPath) Match(p string) bool { return p == string(m) }
+
+func (m matchPath) Descend(string) bool { return false }
+
+// Watch call fn with the events on path, the whole tree under it if it is a
+// directory, directories created later included. fn is called from one
+// goroutine, with the events one by one, or in batches with WithDebounce.
+// Renames within the tree are tracked, see Event. stop ends the watch, events
+// not delivered yet are dropped and fn is not called again. It waits for the
+// watch to wind down unless fn is running, so fn can call stop itself.
+func Watch(path string, events EventMask, fn func([]Event), opts ...WatchOption) (stop func() error, err error) {
+	var o watchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var w *Watcher
+	if info.IsDir() {
+		w, err = WatchMatcher(path, matchAll{}, events)
+	} else {
+		w, err = WatchMatcher(filepath.Dir(path), matchPath(path), events)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// running and stopped let stop return at once when called from fn
+	var (
+		mu      sync.Mutex
+		running bool
+		stopped bool
+	)
+	call := func(events []Event) {
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			return
+		}
+		running = true
+		mu.Unlock()
+		fn(events)
+		mu.Lock()
+		running = false
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for err := range w.Errors() {
+			if o.onError != nil {
+				o.onError(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		dispatch(w.Events(), o.debounce, call)
+	}()
+
+	return func() error {
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			return nil
+		}
+		stopped = true
+		wait := !running
+		mu.Unlock()
+		err := w.Close()
+		if wait {
+			wg.Wait()
+		}
+		return err
+	}, nil
+}
+
+// dispatch call fn with the events of ch, batched until no event arrived for
+// debounce if it is not zero
+func dispatch(ch <-chan Event, debounce time.Duration, fn func([]Event)) {
+	if debounce <= 0 {
+		for e := range ch {
+			fn([]Event{e})
+		}
+		return
+	}
+
+	var (
+		batch []Event
+		index = make(map[string]int)
+		timer *time.Timer
+		fire  <-chan time.Time
+	)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+			if i, ok := index[e.Path]; ok {
+				batch[i].Op |= e.Op
+				if len(e.OldPath) > 0 {
+					batch[i].OldPath = e.OldPath
+				}
+			} else {
+				index[e.Path] = len(batch)
+				batch = append(batch, e)
+			}
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+			} else {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(debounce)
+			}
+			fire = timer.C
+		case <-fire:
+			timer = nil
+			fire = nil
+			fn(batch)
+			batch = nil
+			index = make(map[string]int)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package dir
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+var ErrEventOverflow = errors.New("Inotify event queue overflowed, events were lost")
+
+const inotifyMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_DELETE | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// Watcher delivers file system events, backed by inotify on Linux
+type Watcher struct {
+	f    *os.File
+	fd   int
+	root string
+	m    PathMatcher
+	mask EventMask
+
+	mu    sync.Mutex
+	wds   map[int]string
+	paths map[string]int
+	// the paths moved away, by inotify cookie, to pair them with where they moved to
+	moves map[uint32]string
+
+	events chan Event
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newWatcher(root string, m PathMatcher, events EventMask) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		// a non-blocking fd is handled by the runtime poller, so Close unblocks Read
+		f:      os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		root:   filepath.Clean(root),
+		m:      m,
+		mask:   events,
+		wds:    make(map[int]string),
+		paths:  make(map[string]int),
+		moves:  make(map[uint32]string),
+		events: make(chan Event, 64),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+
+	err = w.addTree(w.root, false)
+	if err != nil {
+		w.f.Close()
+		return nil, err
+	}
+
+	go w.readEvents()
+
+	return w, nil
+}
+
+// Events the channel events are delivered on, it is closed by Close
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors the channel errors are delivered on, it is closed by Close
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stop watching
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.f.Close()
+	})
+	return err
+}
+
+// addTree watch dir and the directories under it that can contain matching paths,
+// if emit is true Create events are sent for the existing matching paths, which
+// were created before the watch was in place.
+func (w *Watcher) addTree(dir string, emit bool) error {
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if p == dir && !emit {
+				return err
+			}
+			// vanished in the meantime
+			return nil
+		}
+		if p != dir && emit && w.mask&Create != 0 && w.m.Match(p) {
+			w.send(Event{Path: p, Op: Create})
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if p != dir && !descend(w.m, p) {
+			return filepath.SkipDir
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, p, inotifyMask|unix.IN_ONLYDIR)
+		if err != nil {
+			if p != dir && (err == unix.ENOENT || err == unix.ENOTDIR) {
+				return nil
+			}
+			return &os.PathError{Op: "inotify_add_watch", Path: p, Err: err}
+		}
+		w.mu.Lock()
+		w.wds[wd] = p
+		w.paths[p] = wd
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+// removeTree stop watching dir and the directories under it
+func (w *Watcher) removeTree(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for p, wd := range w.paths {
+		if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.paths, p)
+			delete(w.wds, wd)
+		}
+	}
+}
+
+func (w *Watcher) send(e Event) {
+	select {
+	case w.events <- e:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) readEvents() {
+	defer close(w.errors)
+	defer close(w.events)
+
+	buf := make([]byte, (unix.SizeofInotifyEvent+unix.NAME_MAX+1)*64)
+	for {
+		n, err := w.f.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			case w.errors <- err:
+			}
+			return
+		}
+
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			name := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(raw.Len)]
+			w.handle(int(raw.Wd), raw.Mask, raw.Cookie, string(bytes.TrimRight(name, "\x00")))
+			off += unix.SizeofInotifyEvent + int(raw.Len)
+		}
+	}
+}
+
+func (w *Watcher) handle(wd int, mask, cookie uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		select {
+		case w.errors <- ErrEventOverflow:
+		default:
+		}
+		return
+	}
+
+	w.mu.Lock()
+	dir, ok := w.wds[wd]
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if mask&unix.IN_IGNORED != 0 {
+		w.mu.Lock()
+		delete(w.wds, wd)
+		if w.paths[dir] == wd {
+			delete(w.paths, dir)
+		}
+		w.mu.Unlock()
+		return
+	}
+
+	if mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0 {
+		// reported to the parent directory as well, except for the root
+		w.removeTree(dir)
+		if dir == w.root && w.mask&Remove != 0 && w.m.Match(dir) {
+			w.send(Event{Path: dir, Op: Remove})
+		}
+		return
+	}
+
+	path := filepath.Join(dir, name)
+
+	var op EventMask
+	switch {
+	case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		op = Create
+	case mask&unix.IN_MODIFY != 0:
+		op = Write
+	case mask&unix.IN_DELETE != 0:
+		op = Remove
+	case mask&unix.IN_MOVED_FROM != 0:
+		op = Rename
+	case mask&unix.IN_ATTRIB != 0:
+		op = Chmod
+	}
+
+	var old string
+	switch {
+	case mask&unix.IN_MOVED_FROM != 0:
+		// moves out of the tree are never paired, don't let them pile up
+		if len(w.moves) >= 1024 {
+			w.moves = make(map[uint32]string)
+		}
+		w.moves[cookie] = path
+	case mask&unix.IN_MOVED_TO != 0:
+		old = w.moves[cookie]
+		delete(w.moves, cookie)
+	}
+
+	if op&w.mask != 0 && w.m.Match(path) {
+		w.send(Event{Path: path, Op: op, OldPath: old})
+	}
+
+	if mask&unix.IN_ISDIR != 0 {
+		switch op {
+		case Create:
+			if descend(w.m, path) {
+				w.addTree(path, true)
+			}
+		case Remove, Rename:
+			w.removeTree(path)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package dir
+
+// Watcher delivers file system events, only supported on Linux for now
+type Watcher struct{}
+
+func newWatcher(root string, m PathMatcher, events EventMask) (*Watcher, error) {
+	return nil, ErrWatchUnsupported
+}
+
+// Events the channel events are delivered on
+func (w *Watcher) Events() <-chan Event {
+	return nil
+}
+
+// Errors the channel errors are delivered on
+func (w *Watcher) Errors() <-chan error {
+	return nil
+}
+
+// Close stop watching
+func (w *Watcher) Close() error {
+	return nil
+}
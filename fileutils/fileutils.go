@@ -33,8 +33,23 @@ func Touch(path string) error {
 	return err
 }
 
+type copyOptions struct {
+	preserveTimes bool
+}
+
+// CopyOption configures Copy
+type CopyOption func(*copyOptions)
+
+// PreserveTimes give the copies the access and modification times of the
+// sources, with nanosecond precision
+func PreserveTimes() CopyOption {
+	return func(o *copyOptions) {
+		o.preserveTimes = true
+	}
+}
+
 //cp copy a single file to another file or directory
-func cp(source, destination, original string) error {
+func cp(source, destination, original string, o copyOptions) error {
 	// source always exists and can be file only
 	s, err := ioutil.ReadFile(source)
 	if err != nil {
@@ -66,6 +81,17 @@ func cp(source, destination, original string) error {
 		return err
 	}
 
+	if o.preserveTimes {
+		t, err := dir.GetTimes(source)
+		if err != nil {
+			return err
+		}
+		err = dir.SetTimes(destination, t.Access, t.Modify)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(original) > 0 {
 		err := os.RemoveAll(original)
 		if err != nil {
@@ -165,14 +191,22 @@ func copy(source, destination string, fn func(s, d, o string) error) error {
 }
 
 // Copy like Linux's cp command, copy a file/dirctory to another place.
-func Copy(src, dest string) error {
+func Copy(src, dest string, opts ...CopyOption) error {
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	fn := func(s, d, orig string) error {
+		return cp(s, d, orig, o)
+	}
+
 	sources, err := extglob.Expand(internal.Str2bytes(src))
 	if err != nil {
 		return err
 	}
 	// sources are always valid files, the check is in extglob's validFunc
 	for _, v := range sources {
-		err1 := copy(v, dest, cp)
+		err1 := copy(v, dest, fn)
 		if err1 != nil {
 			return err1
 		}
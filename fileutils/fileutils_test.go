@@ -1,7 +1,13 @@
 package fileutils
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/marguerite/go-stdlib/dir"
 )
 
 func TestCopy(t *testing.T) {
@@ -11,3 +17,18 @@ func TestCopy(t *testing.T) {
 		t.Error("fileutils.Copy test failed")
 	}
 }
+
+func TestCopyPreserveTimes(t *testing.T) {
+	d := t.TempDir()
+	src := filepath.Join(d, "src")
+	ioutil.WriteFile(src, []byte("src"), 0644)
+	mtime := time.Unix(1600000000, 123456789)
+	os.Chtimes(src, mtime, mtime)
+
+	dest := filepath.Join(d, "out", "dest")
+	err := cp(src, dest, "", copyOptions{preserveTimes: true})
+	times, _ := dir.GetTimes(dest)
+	if !times.Modify.Equal(mtime) || err != nil {
+		t.Errorf("fileutils.Copy PreserveTimes test failed, expecting %s, got %s, err %v", mtime, times.Modify, err)
+	}
+}
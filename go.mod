@@ -1,9 +1,10 @@
 module github.com/marguerite/go-stdlib
 
-go 1.15
+go 1.16
 
 require (
 	github.com/marguerite/go-gnulib v0.0.0-20210318090450-407d620c3bb7
-	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e
+	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c
 	golang.org/x/text v0.3.6
 )
@@ -0,0 +1,140 @@
+package httputils
+
+import (
+	"sync"
+	"time"
+)
+
+// AddressCache caches the result of LocalIPAddress for a TTL, and notifies
+// subscribers when the address changes, eg: an interface goes up or down.
+// Changes are detected with netlink on Linux and by polling every TTL elsewhere.
+type AddressCache struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	addr string
+	err  error
+	at   time.Time
+	subs map[chan string]struct{}
+	stop chan struct{}
+}
+
+var defaultAddressCache = NewAddressCache(30 * time.Second)
+
+// NewAddressCache return an AddressCache keeping the address for ttl
+func NewAddressCache(ttl time.Duration) *AddressCache {
+	return &AddressCache{ttl: ttl, subs: make(map[chan string]struct{})}
+}
+
+// CachedLocalIPAddress LocalIPAddress cached for 30 seconds
+func CachedLocalIPAddress() (string, error) {
+	return defaultAddressCache.LocalIPAddress()
+}
+
+// LocalIPAddress the cached address, looked up again once the TTL expired
+func (c *AddressCache) LocalIPAddress() (string, error) {
+	c.mu.Lock()
+	if !c.at.IsZero() && time.Since(c.at) < c.ttl {
+		defer c.mu.Unlock()
+		return c.addr, c.err
+	}
+	c.mu.Unlock()
+
+	addr, _, err := c.refresh()
+	return addr, err
+}
+
+// refresh look the address up and tell whether it changed
+func (c *AddressCache) refresh() (string, bool, error) {
+	addr, err := LocalIPAddress()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := !c.at.IsZero() && addr != c.addr
+	c.addr, c.err, c.at = addr, err, time.Now()
+	return addr, changed, err
+}
+
+// Subscribe return a channel receiving the new address every time it
+// changes, the empty string when the device is disconnected, and a function
+// to unsubscribe, which closes the channel. Only the latest change is kept
+// for slow receivers.
+func (c *AddressCache) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, 1)
+
+	c.mu.Lock()
+	c.subs[ch] = struct{}{}
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+		go c.watch(c.stop)
+	}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.subs, ch)
+			close(ch)
+			if len(c.subs) == 0 {
+				close(c.stop)
+				c.stop = nil
+			}
+		})
+	}
+}
+
+func (c *AddressCache) watch(stop chan struct{}) {
+	// the address to compare the first change to
+	c.refresh()
+
+	changes := interfaceChanges(stop, c.ttl)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-changes:
+		}
+
+		addr, changed, _ := c.refresh()
+		if !changed {
+			continue
+		}
+
+		c.mu.Lock()
+		for ch := range c.subs {
+			// replace the change the subscriber didn't receive yet
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- addr
+		}
+		c.mu.Unlock()
+	}
+}
+
+// pollChanges signal a possible change every interval until stop is closed
+func pollChanges(stop <-chan struct{}, interval time.Duration) <-chan struct{} {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ch := make(chan struct{}, 1)
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return ch
+}
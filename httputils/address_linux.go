@@ -0,0 +1,60 @@
+package httputils
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// interfaceChanges signal the changes of links and addresses the kernel
+// reports over netlink, falling back to polling every interval
+func interfaceChanges(stop <-chan struct{}, interval time.Duration) <-chan struct{} {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_ROUTE)
+	if err != nil {
+		return pollChanges(stop, interval)
+	}
+	err = unix.Bind(fd, &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR,
+	})
+	if err != nil {
+		unix.Close(fd)
+		return pollChanges(stop, interval)
+	}
+
+	// a non-blocking fd is handled by the runtime poller, so Close unblocks Read
+	f := os.NewFile(uintptr(fd), "netlink")
+	go func() {
+		<-stop
+		f.Close()
+	}()
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, os.Getpagesize())
+		for {
+			// the messages are not parsed, any of them may change the address
+			if _, err := f.Read(buf); err != nil {
+				// netlink broke, keep on polling
+				poll := pollChanges(stop, interval)
+				for {
+					select {
+					case <-stop:
+						return
+					case <-poll:
+						select {
+						case ch <- struct{}{}:
+						default:
+						}
+					}
+				}
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch
+}
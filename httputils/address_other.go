@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package httputils
+
+import "time"
+
+// interfaceChanges signal a possible change every interval
+func interfaceChanges(stop <-chan struct{}, interval time.Duration) <-chan struct{} {
+	return pollChanges(stop, interval)
+}
@@ -0,0 +1,329 @@
+package httputils
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithDiskCache cache the responses to GET requests in dir, serving them while
+// they are fresh according to their Cache-Control and Expires headers and
+// revalidating them with their ETag or Last-Modified afterwards. Entries are
+// partitioned by the request headers the Vary header names, a "Vary: *"
+// response is never cached. Like a shared cache, it doesn't store responses to
+// requests with an Authorization header, responses setting cookies and
+// "Cache-Control: private" ones, unless WithCachePrivate says otherwise.
+func WithDiskCache(dir string) ClientOption {
+	return func(o *clientOptions) {
+		o.cacheDir = dir
+	}
+}
+
+// WithCachePrivate also cache authorized, cookie setting and private
+// responses, for caches only one user reads
+func WithCachePrivate() ClientOption {
+	return func(o *clientOptions) {
+		o.cachePrivate = true
+	}
+}
+
+type diskCache struct {
+	dir     string
+	private bool
+}
+
+func cache(rt http.RoundTripper, c diskCache) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || len(req.Header.Get("Range")) > 0 ||
+			hasDirective(req.Header, "no-store") || (!c.private && len(req.Header.Get("Authorization")) > 0) {
+			return rt.RoundTrip(req)
+		}
+
+		path := c.entryPath(req)
+		cached, stored := c.load(path, req)
+		if cached != nil && !hasDirective(req.Header, "no-cache") && fresh(cached, stored) {
+			return cached, nil
+		}
+
+		if cached != nil {
+			req = req.Clone(req.Context())
+			if etag := cached.Header.Get("ETag"); len(etag) > 0 {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lm := cached.Header.Get("Last-Modified"); len(lm) > 0 {
+				req.Header.Set("If-Modified-Since", lm)
+			}
+		}
+
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			if cached != nil {
+				cached.Body.Close()
+			}
+			return nil, err
+		}
+
+		if cached != nil {
+			if resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				// fresh again for as long as the entry says
+				now := time.Now()
+				os.Chtimes(path, now, now)
+				return cached, nil
+			}
+			cached.Body.Close()
+		}
+
+		if !c.storable(resp) {
+			return resp, nil
+		}
+		return c.store(req, resp)
+	})
+}
+
+// storable whether resp may be stored
+func (c diskCache) storable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || hasDirective(resp.Header, "no-store") {
+		return false
+	}
+	for _, v := range varyNames(resp.Header) {
+		if v == "*" {
+			return false
+		}
+	}
+	if !c.private && (len(resp.Header.Values("Set-Cookie")) > 0 || hasDirective(resp.Header, "private")) {
+		return false
+	}
+	_, ok := maxAge(resp.Header)
+	return ok || len(resp.Header.Get("ETag")) > 0 || len(resp.Header.Get("Last-Modified")) > 0
+}
+
+// store stream resp to the cache while its body is read, the entry is only
+// written once the body is read to the end
+func (c diskCache) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return resp, nil
+	}
+	f, err := ioutil.TempFile(c.dir, ".tmp")
+	if err != nil {
+		return resp, nil
+	}
+
+	// the body is written as read, up to the end of the file
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	h := resp.Header.Clone()
+	h.Del("Transfer-Encoding")
+	h.Write(w)
+	w.WriteString("\r\n")
+
+	resp.Body = &cacheWriter{ReadCloser: resp.Body, w: w, f: f, commit: func() error {
+		// the Vary names of the url decide which entry requests are looked up in
+		vary := strings.Join(varyNames(resp.Header), "\n")
+		if err := writeAtomic(filepath.Join(c.dir, hashOf(req.URL.String())+".vary"), []byte(vary)); err != nil {
+			return err
+		}
+		return os.Rename(f.Name(), c.entryPath(req))
+	}}
+	return resp, nil
+}
+
+// cacheWriter copy what is read from a response body to the temporary file
+// of a cache entry, committed on EOF and dropped if the body is closed before
+type cacheWriter struct {
+	io.ReadCloser
+	w      *bufio.Writer
+	f      *os.File
+	commit func() error
+	done   bool
+}
+
+func (cw *cacheWriter) Read(p []byte) (int, error) {
+	n, err := cw.ReadCloser.Read(p)
+	if cw.done {
+		return n, err
+	}
+	if _, werr := cw.w.Write(p[:n]); werr != nil {
+		cw.drop()
+		return n, err
+	}
+	if err == io.EOF {
+		cw.done = true
+		werr := cw.w.Flush()
+		if err1 := cw.f.Close(); werr == nil {
+			werr = err1
+		}
+		if werr == nil {
+			werr = cw.commit()
+		}
+		if werr != nil {
+			os.Remove(cw.f.Name())
+		}
+	} else if err != nil {
+		cw.drop()
+	}
+	return n, err
+}
+
+func (cw *cacheWriter) Close() error {
+	if !cw.done {
+		cw.drop()
+	}
+	return cw.ReadCloser.Close()
+}
+
+// drop give up on the entry
+func (cw *cacheWriter) drop() {
+	cw.done = true
+	cw.f.Close()
+	os.Remove(cw.f.Name())
+}
+
+// load the cached response to req at path and when it was stored, its body
+// reading the file
+func (c diskCache) load(path string, req *http.Request) (*http.Response, time.Time) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(f), req)
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}
+	}
+	resp.Body = fileBody{resp.Body, f}
+	return resp, info.ModTime()
+}
+
+// fileBody a response body closing the file it is read from
+type fileBody struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (b fileBody) Close() error {
+	b.ReadCloser.Close()
+	return b.f.Close()
+}
+
+// entryPath the file of the entry for req, keyed by its url and the values
+// of the request headers the last response for the url varied on
+func (c diskCache) entryPath(req *http.Request) string {
+	key := req.URL.String()
+	if b, err := ioutil.ReadFile(filepath.Join(c.dir, hashOf(key)+".vary")); err == nil && len(b) > 0 {
+		for _, name := range strings.Split(string(b), "\n") {
+			key += "\n" + name + ":" + strings.Join(req.Header.Values(name), ",")
+		}
+	}
+	return filepath.Join(c.dir, hashOf(key))
+}
+
+// fresh whether the cached response stored at stored can be served without revalidation
+func fresh(resp *http.Response, stored time.Time) bool {
+	if hasDirective(resp.Header, "no-cache") {
+		return false
+	}
+	if d, ok := maxAge(resp.Header); ok {
+		return time.Since(stored) < d
+	}
+	return false
+}
+
+// maxAge the freshness lifetime of a response, from Cache-Control or Expires
+func maxAge(h http.Header) (time.Duration, bool) {
+	for _, d := range directives(h) {
+		for _, name := range []string{"s-maxage=", "max-age="} {
+			if strings.HasPrefix(d, name) {
+				if n, err := strconv.Atoi(strings.Trim(d[len(name):], "\"")); err == nil {
+					return time.Duration(n) * time.Second, true
+				}
+			}
+		}
+	}
+	if e := h.Get("Expires"); len(e) > 0 {
+		t, err := http.ParseTime(e)
+		if err != nil {
+			// invalid dates, like "0", mean already expired
+			return 0, true
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		return t.Sub(date), true
+	}
+	return 0, false
+}
+
+func directives(h http.Header) []string {
+	var res []string
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			res = append(res, strings.ToLower(strings.TrimSpace(d)))
+		}
+	}
+	return res
+}
+
+// hasDirective whether the Cache-Control headers of h hold the directive name
+func hasDirective(h http.Header, name string) bool {
+	for _, d := range directives(h) {
+		if d == name || strings.HasPrefix(d, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// varyNames the canonical header names of the Vary headers of h, sorted
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, n := range strings.Split(v, ",") {
+			if n = strings.TrimSpace(n); len(n) > 0 {
+				names = append(names, http.CanonicalHeaderKey(n))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hashOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeAtomic write b to a temporary file renamed to path
+func writeAtomic(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,385 @@
+package httputils
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvPrefix the prefix of the environment variables read by FromEnv
+const EnvPrefix = "HTTPUTILS_"
+
+type clientOptions struct {
+	proxy     string
+	caFiles   []string
+	insecure  bool
+	timeout   time.Duration
+	rate      float64
+	burst     int
+	username  string
+	password  string
+	token     string
+	userAgent string
+	coolDown  *CoolDown
+	coalesce  bool
+
+	systemProxy   bool
+	insecureHosts map[string]bool
+	hostCAFiles   map[string][]string
+	cacheDir      string
+	cachePrivate  bool
+	referer       string
+	noReferer     bool
+	origin        string
+	originPolicy  OriginPolicy
+}
+
+// ClientOption configures the client built by NewClient
+type ClientOption func(*clientOptions)
+
+// WithProxy use the proxy at rawurl instead of "http(s)?_proxy"
+func WithProxy(rawurl string) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = rawurl
+	}
+}
+
+// WithCAFile trust the PEM encoded certificates in files in addition to the system pool
+func WithCAFile(files ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.caFiles = append(o.caFiles, files...)
+	}
+}
+
+// WithInsecure skip TLS certificate verification
+func WithInsecure() ClientOption {
+	return func(o *clientOptions) {
+		o.insecure = true
+	}
+}
+
+// WithTimeout limit the time a whole request may take
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRateLimit allow perSecond requests per second with bursts of burst requests
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(o *clientOptions) {
+		o.rate = perSecond
+		o.burst = burst
+	}
+}
+
+// WithBasicAuth send the credentials with every request, but not to the other hosts redirects lead to
+func WithBasicAuth(username, password string) ClientOption {
+	return func(o *clientOptions) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithBearerToken send "Authorization: Bearer token" like WithBasicAuth sends credentials
+func WithBearerToken(token string) ClientOption {
+	return func(o *clientOptions) {
+		o.token = token
+	}
+}
+
+// WithUserAgent set the User-Agent header of every request
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = ua
+	}
+}
+
+// NewClient return a http client configured by opts. Unlike ProxyClient it
+// verifies certificates and follows redirects unless told otherwise.
+func NewClient(opts ...ClientOption) (*http.Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: o.insecure}
+
+	if len(o.proxy) > 0 {
+		u, err := url.Parse(o.proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(u)
+	} else if o.systemProxy {
+		if p := detectSystemProxy(); p != nil {
+			transport.Proxy = p.proxy
+		}
+	}
+
+	if len(o.caFiles) > 0 {
+		pool, err := certPool(o.caFiles)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	rt, err := perHostTLS(transport, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.rate > 0 {
+		rt = rateLimit(rt, newRateLimiter(o.rate, o.burst))
+	}
+
+	if o.coolDown != nil {
+		rt = coolDown(rt, o.coolDown)
+	}
+
+	if o.coalesce {
+		rt = coalesce(rt)
+	}
+
+	if len(o.cacheDir) > 0 {
+		rt = cache(rt, diskCache{o.cacheDir, o.cachePrivate})
+	}
+
+	if len(o.username) > 0 || len(o.token) > 0 || len(o.userAgent) > 0 ||
+		len(o.referer) > 0 || o.noReferer || len(o.origin) > 0 || o.originPolicy != OriginKeep {
+		rt = setHeaders(rt, o)
+	}
+
+	return &http.Client{Transport: rt, Timeout: o.timeout}, nil
+}
+
+// LoadClientConfig read client options from an INI/TOML like file:
+//
+//	[http]
+//	proxy = "http://proxy:3128"
+//	ca_file = /etc/ssl/internal.pem
+//	timeout = 30s
+//	rate_limit = 5
+//	burst = 10
+//
+// Sections are ignored, the keys are the same as the ones read by FromEnv.
+func LoadClientConfig(path string) (ClientOption, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys, values []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' || line[0] == '[' {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: missing '='", path, n)
+		}
+		keys = append(keys, strings.ToLower(strings.TrimSpace(line[:i])))
+		values = append(values, strings.Trim(strings.TrimSpace(line[i+1:]), "\"'"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return parseClientOptions(keys, values)
+}
+
+// FromEnv read client options from the HTTPUTILS_* environment variables,
+// eg: HTTPUTILS_PROXY, HTTPUTILS_CA_FILE, HTTPUTILS_TIMEOUT, HTTPUTILS_RATE_LIMIT,
+// HTTPUTILS_BURST, HTTPUTILS_USERNAME, HTTPUTILS_PASSWORD, HTTPUTILS_TOKEN,
+// HTTPUTILS_INSECURE, HTTPUTILS_INSECURE_HOST, HTTPUTILS_USER_AGENT, HTTPUTILS_COALESCE,
+// HTTPUTILS_SYSTEM_PROXY, HTTPUTILS_CACHE_DIR, HTTPUTILS_CACHE_PRIVATE, HTTPUTILS_REFERER,
+// HTTPUTILS_NO_REFERER and HTTPUTILS_ORIGIN
+func FromEnv() (ClientOption, error) {
+	var keys, values []string
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, EnvPrefix) {
+			continue
+		}
+		i := strings.Index(v, "=")
+		keys = append(keys, strings.ToLower(v[len(EnvPrefix):i]))
+		values = append(values, v[i+1:])
+	}
+	return parseClientOptions(keys, values)
+}
+
+// parseClientOptions validate the key/value pairs and turn them into a ClientOption
+func parseClientOptions(keys, values []string) (ClientOption, error) {
+	var o clientOptions
+	for i, k := range keys {
+		err := setClientOption(&o, k, values[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(o1 *clientOptions) {
+		for i, k := range keys {
+			setClientOption(o1, k, values[i])
+		}
+	}, nil
+}
+
+func setClientOption(o *clientOptions, key, value string) error {
+	var err error
+	switch key {
+	case "proxy":
+		_, err = url.Parse(value)
+		o.proxy = value
+	case "ca_file":
+		for _, f := range strings.Split(value, ",") {
+			o.caFiles = append(o.caFiles, strings.TrimSpace(f))
+		}
+	case "insecure":
+		o.insecure, err = strconv.ParseBool(value)
+	case "timeout":
+		o.timeout, err = time.ParseDuration(value)
+		if err != nil {
+			var n int
+			n, err = strconv.Atoi(value)
+			o.timeout = time.Duration(n) * time.Second
+		}
+	case "rate_limit":
+		o.rate, err = strconv.ParseFloat(value, 64)
+	case "burst":
+		o.burst, err = strconv.Atoi(value)
+	case "username":
+		o.username = value
+	case "password":
+		o.password = value
+	case "token":
+		o.token = value
+	case "user_agent":
+		o.userAgent = value
+	case "coalesce":
+		o.coalesce, err = strconv.ParseBool(value)
+	case "system_proxy":
+		o.systemProxy, err = strconv.ParseBool(value)
+	case "referer":
+		o.referer = value
+	case "no_referer":
+		o.noReferer, err = strconv.ParseBool(value)
+	case "origin":
+		o.origin = value
+	case "cache_dir":
+		o.cacheDir = value
+	case "cache_private":
+		o.cachePrivate, err = strconv.ParseBool(value)
+	case "insecure_host":
+		for _, h := range strings.Split(value, ",") {
+			WithInsecureHost(strings.TrimSpace(h))(o)
+		}
+	default:
+		return fmt.Errorf("unknown client option %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for client option %s: %w", value, key, err)
+	}
+	return nil
+}
+
+// roundTripperFunc turns a function into a http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func setHeaders(rt http.RoundTripper, o clientOptions) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		if len(o.userAgent) > 0 {
+			req.Header.Set("User-Agent", o.userAgent)
+		}
+		// like http.Client, keep the credentials from the hosts redirects lead to
+		if sameHostAsFirst(req) {
+			if len(o.token) > 0 {
+				req.Header.Set("Authorization", "Bearer "+o.token)
+			} else if len(o.username) > 0 {
+				req.SetBasicAuth(o.username, o.password)
+			}
+		}
+		setRefererOrigin(req, o)
+		return rt.RoundTrip(req)
+	})
+}
+
+// sameHostAsFirst whether req goes to the host of the request that started its redirect chain
+func sameHostAsFirst(req *http.Request) bool {
+	first := req
+	// req.Response is the redirect that led to req
+	for first.Response != nil && first.Response.Request != nil {
+		first = first.Response.Request
+	}
+	return first.URL.Host == req.URL.Host
+}
+
+func rateLimit(rt http.RoundTripper, l *rateLimiter) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		err := l.wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		return rt.RoundTrip(req)
+	})
+}
+
+// rateLimiter a token bucket
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait block until a token is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		d := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package httputils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClientRedirectAuth(t *testing.T) {
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+	}))
+	defer other.Close()
+
+	var sent string
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Authorization")
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer first.Close()
+
+	client, err := NewClient(WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("[httputils]: NewClient test failed with %s", err)
+	}
+	resp, err := client.Get(first.URL)
+	if err != nil {
+		t.Fatalf("[httputils]: NewClient test failed with %s", err)
+	}
+	resp.Body.Close()
+	if sent != "Bearer secret" || len(leaked) > 0 {
+		t.Errorf("[httputils]: NewClient test failed, expecting the token sent to the first host only, got %q and %q", sent, leaked)
+	}
+}
+
+func TestClientRedirectOrigin(t *testing.T) {
+	var origins []string
+	var other *httptest.Server
+	other = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins = append(origins, r.Header.Get("Origin"))
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, other.URL+"/next", http.StatusFound)
+		}
+	}))
+	defer other.Close()
+
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins = append(origins, r.Header.Get("Origin"))
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer first.Close()
+
+	client, err := NewClient(WithOrigin("https://example.com"), WithOriginPolicy(OriginStrip))
+	if err != nil {
+		t.Fatalf("[httputils]: NewClient test failed with %s", err)
+	}
+	resp, err := client.Get(first.URL)
+	if err != nil {
+		t.Fatalf("[httputils]: NewClient test failed with %s", err)
+	}
+	resp.Body.Close()
+	correct := []string{"https://example.com", "", ""}
+	if !reflect.DeepEqual(origins, correct) {
+		t.Errorf("[httputils]: NewClient test failed, expecting origins %q along the redirects, got %q", correct, origins)
+	}
+}
@@ -0,0 +1,97 @@
+package httputils
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoDate the server answered without a usable Date header
+var ErrNoDate = errors.New("No Date header in response")
+
+// ClockSkew estimate how far the local clock is ahead of (positive) or behind
+// (negative) the clocks of the servers at urls, trusted to be synchronized,
+// from the Date headers of HEAD requests sent concurrently. Date only has a
+// one second resolution, so is the estimate. It returns the median skew of the
+// servers that answered, an error if none did. A skew of a few minutes makes
+// TLS and signature verification fail with puzzling errors. A skew beyond the
+// validity of the certificates of https urls fails their TLS handshake too, the
+// error then says so, plain http urls can still measure it. client is
+// http.DefaultClient if nil.
+func ClockSkew(ctx context.Context, client *http.Client, urls ...string) (time.Duration, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		skews []time.Duration
+		errs  []string
+	)
+
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			d, err := clockSkew(ctx, client, u)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", u, err))
+				return
+			}
+			skews = append(skews, d)
+		}(u)
+	}
+	wg.Wait()
+
+	if len(skews) == 0 {
+		if len(errs) == 0 {
+			return 0, errors.New("no url to estimate the clock skew from")
+		}
+		return 0, fmt.Errorf("failed to estimate the clock skew: %s", strings.Join(errs, "; "))
+	}
+
+	sort.Slice(skews, func(i, j int) bool { return skews[i] < skews[j] })
+	if n := len(skews); n%2 == 0 {
+		return (skews[n/2-1] + skews[n/2]) / 2, nil
+	}
+	return skews[len(skews)/2], nil
+}
+
+func clockSkew(ctx context.Context, client *http.Client, rawurl string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawurl, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	var cert x509.CertificateInvalidError
+	if errors.As(err, &cert) && cert.Reason == x509.Expired {
+		return 0, fmt.Errorf("%w, the local clock may be too far off to verify certificates, try a plain http url", err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	rtt := time.Since(start)
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, ErrNoDate
+	}
+
+	// the server truncated its time to the second somewhere during the round trip,
+	// compare it to the local time halfway through
+	local := start.Add(rtt / 2)
+	server := date.Add(500 * time.Millisecond)
+	return local.Sub(server).Round(time.Millisecond), nil
+}
@@ -0,0 +1,22 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClockSkew(t *testing.T) {
+	// a self-signed certificate only ts.Client trusts
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	}))
+	defer ts.Close()
+
+	d, err := ClockSkew(context.Background(), ts.Client(), ts.URL)
+	if err != nil || d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("[httputils]: ClockSkew test failed, expecting about 1h, got %s, err %v", d, err)
+	}
+}
@@ -0,0 +1,130 @@
+package httputils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WithCoalescing share one upstream call between identical GET requests in
+// flight at the same time, eg: many goroutines fetching the same metadata URL.
+// Requests are identical if their URLs and headers are. A response nobody
+// waited for is returned untouched. Otherwise its body is read into memory once
+// and every caller gets its own copy, unless it is larger than
+// maxCoalescedBody, then the waiters send their own requests instead.
+func WithCoalescing() ClientOption {
+	return func(o *clientOptions) {
+		o.coalesce = true
+	}
+}
+
+// maxCoalescedBody the largest response body buffered for the waiters
+const maxCoalescedBody = 1 << 20
+
+// errBodyTooLarge tells the waiters to send their own requests
+var errBodyTooLarge = errors.New("Coalesced response body too large")
+
+// flight a GET request in flight, shared by the requests waiting for it
+type flight struct {
+	done    chan struct{}
+	waiters int
+	resp    *http.Response
+	body    []byte
+	err     error
+}
+
+// readCloser a reader closed by another closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func coalesce(rt http.RoundTripper) http.RoundTripper {
+	var mu sync.Mutex
+	flights := make(map[string]*flight)
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || (req.Body != nil && req.Body != http.NoBody) {
+			return rt.RoundTrip(req)
+		}
+
+		key := requestKey(req)
+		mu.Lock()
+		f, ok := flights[key]
+		if ok {
+			f.waiters++
+		} else {
+			f = &flight{done: make(chan struct{})}
+			flights[key] = f
+		}
+		mu.Unlock()
+
+		if !ok {
+			resp, err := rt.RoundTrip(req)
+			mu.Lock()
+			delete(flights, key)
+			waiters := f.waiters
+			mu.Unlock()
+			defer close(f.done)
+
+			if err != nil || waiters == 0 {
+				// share the error, or pass the response through when nobody waits
+				f.err = err
+				return resp, err
+			}
+
+			f.resp = resp
+			f.body, f.err = ioutil.ReadAll(io.LimitReader(resp.Body, maxCoalescedBody+1))
+			if f.err != nil {
+				resp.Body.Close()
+				return nil, f.err
+			}
+			if len(f.body) > maxCoalescedBody {
+				f.err = errBodyTooLarge
+				resp.Body = readCloser{io.MultiReader(bytes.NewReader(f.body), resp.Body), resp.Body}
+				return resp, nil
+			}
+			resp.Body.Close()
+		} else {
+			select {
+			case <-req.Context().Done():
+				mu.Lock()
+				f.waiters--
+				mu.Unlock()
+				return nil, req.Context().Err()
+			case <-f.done:
+			}
+			if f.err == errBodyTooLarge {
+				return rt.RoundTrip(req)
+			}
+			// the request we waited for was canceled by its caller, not by us
+			if f.err != nil && (errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded)) && req.Context().Err() == nil {
+				return rt.RoundTrip(req)
+			}
+		}
+
+		if f.err != nil {
+			return nil, f.err
+		}
+		resp := *f.resp
+		resp.Header = f.resp.Header.Clone()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(f.body))
+		resp.Request = req
+		return &resp, nil
+	})
+}
+
+// requestKey identify a request by its URL and headers
+func requestKey(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.URL.String())
+	b.WriteString("\n")
+	// Header.Write sorts the keys
+	req.Header.Write(&b)
+	return b.String()
+}
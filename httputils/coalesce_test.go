@@ -0,0 +1,69 @@
+package httputils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		calls int32
+	}{
+		{"small", 10, 1},
+		{"large", maxCoalescedBody + 10, 3},
+	}
+	for _, tc := range tests {
+		body := bytes.Repeat([]byte("a"), tc.size)
+		var calls int32
+		entered := make(chan struct{}, 3)
+		release := make(chan struct{})
+		rt := coalesce(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			entered <- struct{}{}
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+		}))
+
+		var wg sync.WaitGroup
+		sizes := make([]int, 3)
+		for i := range sizes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "http://example.com/meta", nil)
+				resp, err := rt.RoundTrip(req)
+				if err != nil {
+					t.Errorf("[httputils]: coalesce %s test failed with %s", tc.name, err)
+					return
+				}
+				b, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				sizes[i] = len(b)
+			}(i)
+			if i == 0 {
+				<-entered
+			}
+		}
+		// let the waiters join the flight
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for _, n := range sizes {
+			if n != tc.size {
+				t.Errorf("[httputils]: coalesce %s test failed, expecting bodies of %d bytes, got %v", tc.name, tc.size, sizes)
+				break
+			}
+		}
+		if calls != tc.calls {
+			t.Errorf("[httputils]: coalesce %s test failed, expecting %d upstream calls, got %d", tc.name, tc.calls, calls)
+		}
+	}
+}
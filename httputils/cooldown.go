@@ -0,0 +1,129 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CoolDown tracks the hosts that answered 429 Too Many Requests with a
+// Retry-After header. Requests to a host in cool-down wait until it is over
+// instead of piling on. Share one CoolDown between clients to share the state.
+type CoolDown struct {
+	mu    sync.Mutex
+	hosts map[string]time.Time
+	// OnCoolDown is called when a host enters cool-down
+	OnCoolDown func(host string, until time.Time)
+	// OnWait is called before a request waits d for the cool-down of host to end
+	OnWait func(host string, d time.Duration)
+}
+
+// NewCoolDown return an empty CoolDown
+func NewCoolDown() *CoolDown {
+	return &CoolDown{hosts: make(map[string]time.Time)}
+}
+
+// WithCoolDown pause requests to hosts in cool-down, see CoolDown
+func WithCoolDown(c *CoolDown) ClientOption {
+	return func(o *clientOptions) {
+		o.coolDown = c
+	}
+}
+
+// Until the time the cool-down of host ends, zero if host is not in cool-down
+func (c *CoolDown) Until(host string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.hosts[host]
+	if !ok || time.Now().After(t) {
+		return time.Time{}
+	}
+	return t
+}
+
+// Hosts a snapshot of the hosts currently in cool-down
+func (c *CoolDown) Hosts() map[string]time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := make(map[string]time.Time, len(c.hosts))
+	now := time.Now()
+	for k, v := range c.hosts {
+		if now.Before(v) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// Set put host in cool-down until t
+func (c *CoolDown) Set(host string, t time.Time) {
+	c.mu.Lock()
+	if c.hosts == nil {
+		c.hosts = make(map[string]time.Time)
+	}
+	// a shorter Retry-After from a racing request does not shorten the cool-down
+	if t.After(c.hosts[host]) {
+		c.hosts[host] = t
+	}
+	c.mu.Unlock()
+	if c.OnCoolDown != nil {
+		c.OnCoolDown(host, t)
+	}
+}
+
+// wait block until the cool-down of host is over or ctx is done
+func (c *CoolDown) wait(ctx context.Context, host string) error {
+	for {
+		until := c.Until(host)
+		if until.IsZero() {
+			return nil
+		}
+		d := time.Until(until)
+		if c.OnWait != nil {
+			c.OnWait(host, d)
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func coolDown(rt http.RoundTripper, c *CoolDown) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		host := req.URL.Host
+		err := c.wait(req.Context(), host)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				c.Set(host, time.Now().Add(d))
+			}
+		}
+		return resp, nil
+	})
+}
+
+// retryAfter parse the Retry-After header, which is either seconds or a http date
+func retryAfter(s string) (time.Duration, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
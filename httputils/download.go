@@ -0,0 +1,271 @@
+package httputils
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoSidecar        = errors.New("No sidecar checksum file found")
+	ErrChecksumMismatch = errors.New("Checksum mismatch")
+)
+
+// DefaultSidecarPatterns the conventional places a checksum file is published next to an artifact.
+// {url} is replaced by the artifact url, {dir} by the url of its parent directory
+// and {name} by the artifact file name.
+var DefaultSidecarPatterns = []string{
+	"{url}.sha256",
+	"{url}.sha512",
+	"{dir}/SHA256SUMS",
+	"{dir}/SHA512SUMS",
+}
+
+type downloadOptions struct {
+	sidecar  []string
+	required bool
+	segments int
+}
+
+// DownloadOption configures Download
+type DownloadOption func(*downloadOptions)
+
+// WithSidecarChecksum verify the download against the first sidecar checksum file found
+// via patterns, DefaultSidecarPatterns is used if no pattern is given.
+// A missing sidecar is not an error unless RequireChecksum is also used.
+func WithSidecarChecksum(patterns ...string) DownloadOption {
+	return func(o *downloadOptions) {
+		if len(patterns) == 0 {
+			patterns = DefaultSidecarPatterns
+		}
+		o.sidecar = patterns
+	}
+}
+
+// RequireChecksum fail the download with ErrNoSidecar if no checksum can be found
+func RequireChecksum() DownloadOption {
+	return func(o *downloadOptions) {
+		o.required = true
+	}
+}
+
+// Download download rawurl to dest. The content is written to a temporary file
+// next to dest and only renamed to dest after it has been verified.
+func Download(client *http.Client, rawurl, dest string, opts ...DownloadOption) error {
+	var o downloadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var sum string
+	if len(o.sidecar) > 0 {
+		s, err := DownloadChecksumFromSidecar(client, rawurl, o.sidecar...)
+		if err != nil && (!errors.Is(err, ErrNoSidecar) || o.required) {
+			return err
+		}
+		sum = s
+	}
+
+	f, err := partFile(dest)
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	var h hash.Hash
+	if len(sum) > 0 {
+		h = hashForDigest(sum)
+	}
+
+	if o.segments > 1 {
+		_, err = DownloadTo(client, rawurl, f, o.segments)
+		// the segments arrive out of order, hash the whole file afterwards
+		if err == nil && h != nil {
+			_, err = f.Seek(0, io.SeekStart)
+			if err == nil {
+				_, err = io.Copy(h, f)
+			}
+		}
+	} else {
+		err = download(client, rawurl, f, h)
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+
+	if h != nil && hex.EncodeToString(h.Sum(nil)) != sum {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, rawurl)
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+// partFile create a temporary file next to dest to download it to. Unlike
+// ioutil.TempFile its mode is 0666 minus the umask, like os.Create, so dest
+// gets the usual mode once renamed.
+func partFile(dest string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(dest), "."+filepath.Base(dest)+".part")
+	for i := 0; i < 10000; i++ {
+		f, err := os.OpenFile(prefix+strconv.Itoa(rand.Int()), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, fmt.Errorf("no unique temporary file found for %s", dest)
+}
+
+// download stream rawurl to f, hashing it with h if not nil
+func download(client *http.Client, rawurl string, f *os.File, h hash.Hash) error {
+	resp, err := client.Get(rawurl)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %s", rawurl, resp.Status)
+	}
+
+	var w io.Writer = f
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// DownloadChecksumFromSidecar find the checksum of rawurl in the sidecar files
+// described by patterns. Both single digest files (artifact.sha256) and sum lists
+// (SHA256SUMS, GNU or BSD format) are understood, a bare digest is only taken
+// from the former, whose patterns contain {url} or {name}. The query of rawurl
+// is kept after {url}. The lowercase hex digest is returned. Sidecars failing
+// to download are skipped, the error is wrapped in ErrNoSidecar if none is found.
+func DownloadChecksumFromSidecar(client *http.Client, rawurl string, patterns ...string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(patterns) == 0 {
+		patterns = DefaultSidecarPatterns
+	}
+
+	name := path.Base(u.Path)
+	base := *u
+	base.RawQuery = ""
+	base.Fragment = ""
+	d := base
+	d.Path = path.Dir(u.Path)
+	r := strings.NewReplacer("{url}", base.String(), "{dir}", strings.TrimSuffix(d.String(), "/"), "{name}", name)
+
+	var last error
+	for _, p := range patterns {
+		sidecar := r.Replace(p)
+		if len(u.RawQuery) > 0 && strings.Contains(p, "{url}") && !strings.Contains(sidecar, "?") {
+			sidecar += "?" + u.RawQuery
+		}
+		resp, err := client.Get(sidecar)
+		if err != nil {
+			last = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+		single := strings.Contains(p, "{url}") || strings.Contains(p, "{name}")
+		sum, ok := parseSidecar(resp.Body, name, single)
+		resp.Body.Close()
+		if ok {
+			return sum, nil
+		}
+	}
+	if last != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoSidecar, last)
+	}
+	return "", ErrNoSidecar
+}
+
+// parseSidecar find the digest for name in a checksum file, a bare digest is
+// taken for name if single, the file belonging to the artifact alone
+func parseSidecar(r io.Reader, name string, single bool) (string, bool) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// BSD style: SHA256 (name) = digest
+		if i := strings.Index(line, ") = "); i > 0 {
+			if j := strings.Index(line, " ("); j > 0 && line[j+2:i] == name && isDigest(line[i+4:]) {
+				return strings.ToLower(line[i+4:]), true
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if !isDigest(fields[0]) {
+			continue
+		}
+		// a single digest for the artifact the sidecar belongs to
+		if len(fields) == 1 {
+			if single {
+				return strings.ToLower(fields[0]), true
+			}
+			continue
+		}
+		// GNU style: digest  name or digest *name, name may hold spaces
+		if strings.TrimPrefix(strings.TrimSpace(line[len(fields[0]):]), "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+func isDigest(s string) bool {
+	if hashForDigest(s) == nil {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// hashForDigest guess the hash algorithm from the length of the hex digest
+func hashForDigest(s string) hash.Hash {
+	switch len(s) {
+	case md5.Size * 2:
+		return md5.New()
+	case sha1.Size * 2:
+		return sha1.New()
+	case sha256.Size * 2:
+		return sha256.New()
+	case sha512.Size * 2:
+		return sha512.New()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package httputils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadChecksumFromSidecar(t *testing.T) {
+	digest := strings.Repeat("ab", sha256.Size)
+	other := strings.Repeat("cd", sha256.Size)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/single/a.iso.sha256":
+			if r.URL.Query().Get("token") != "t" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(digest + "\n"))
+		case "/list/SHA256SUMS":
+			w.Write([]byte(other + "\n" + digest + "  my a.iso\n"))
+		case "/bare/SHA256SUMS":
+			w.Write([]byte(other + "\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		url     string
+		correct string
+		err     error
+	}{
+		{ts.URL + "/single/a.iso?token=t", digest, nil},
+		{ts.URL + "/list/my%20a.iso", digest, nil},
+		{ts.URL + "/bare/a.iso", "", ErrNoSidecar},
+	}
+	for _, tc := range tests {
+		sum, err := DownloadChecksumFromSidecar(ts.Client(), tc.url)
+		if sum != tc.correct || !errors.Is(err, tc.err) {
+			t.Errorf("[httputils]: DownloadChecksumFromSidecar test failed for %s, expecting %s, got %s, err %v", tc.url, tc.correct, sum, err)
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	content := []byte("content")
+	h := sha256.Sum256(content)
+	digest := hex.EncodeToString(h[:])
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			w.Write(content)
+		case "/SHA256SUMS":
+			w.Write([]byte(digest + "  a\n"))
+		}
+	}))
+	defer ts.Close()
+
+	// the {url} sidecars fail with a transport error, SHA256SUMS still counts
+	client := ts.Client()
+	rt := client.Transport
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, ".sha256") || strings.HasSuffix(req.URL.Path, ".sha512") {
+			return nil, errors.New("connection reset")
+		}
+		return rt.RoundTrip(req)
+	})
+
+	d := t.TempDir()
+	dest := filepath.Join(d, "a")
+	if err := Download(client, ts.URL+"/a", dest, WithSidecarChecksum(), RequireChecksum()); err != nil {
+		t.Fatalf("[httputils]: Download test failed with %s", err)
+	}
+
+	// the mode the umask gives to a new file
+	f, err := os.OpenFile(filepath.Join(d, "probe"), os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		t.Fatalf("[httputils]: Download test failed with %s", err)
+	}
+	f.Close()
+	info, _ := os.Stat(dest)
+	probe, _ := os.Stat(filepath.Join(d, "probe"))
+	if info.Mode() != probe.Mode() {
+		t.Errorf("[httputils]: Download test failed, expecting mode %s, got %s", probe.Mode(), info.Mode())
+	}
+}
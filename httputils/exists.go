@@ -0,0 +1,141 @@
+package httputils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type existsOptions struct {
+	client *http.Client
+	rate   float64
+	burst  int
+}
+
+// ExistsOption configures ExistsAll
+type ExistsOption func(*existsOptions)
+
+// WithExistsClient check with client instead of http.DefaultClient
+func WithExistsClient(client *http.Client) ExistsOption {
+	return func(o *existsOptions) {
+		o.client = client
+	}
+}
+
+// WithExistsRateLimit allow perSecond requests per second with bursts of burst
+// requests, shared by all the workers
+func WithExistsRateLimit(perSecond float64, burst int) ExistsOption {
+	return func(o *existsOptions) {
+		o.rate = perSecond
+		o.burst = burst
+	}
+}
+
+// ExistsAll check whether the resources at urls exist with concurrency
+// parallel HEAD requests, falling back to a GET of the first byte when the
+// server doesn't allow HEAD. The map tells whether each url exists, 2xx
+// meaning yes and 404/410 no. The urls that could not be checked, because of
+// other statuses or network errors, are missing from it and listed in the error.
+func ExistsAll(ctx context.Context, urls []string, concurrency int, opts ...ExistsOption) (map[string]bool, error) {
+	o := existsOptions{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var limiter *rateLimiter
+	if o.rate > 0 {
+		limiter = newRateLimiter(o.rate, o.burst)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		res  = make(map[string]bool, len(urls))
+		errs []string
+	)
+
+	jobs := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				ok, err := exists(ctx, o.client, limiter, u)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", u, err))
+				} else {
+					res[u] = ok
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[string]struct{}, len(urls))
+	for _, u := range urls {
+		if _, ok := seen[u]; ok {
+			continue
+		}
+		seen[u] = struct{}{}
+		select {
+		case jobs <- u:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return res, fmt.Errorf("failed to check %d urls: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return res, ctx.Err()
+}
+
+func exists(ctx context.Context, client *http.Client, limiter *rateLimiter, rawurl string) (bool, error) {
+	code, err := existsStatus(ctx, client, limiter, http.MethodHead, rawurl)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = existsStatus(ctx, client, limiter, http.MethodGet, rawurl)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case code >= 200 && code < 300:
+		return true, nil
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected status %d", code)
+}
+
+func existsStatus(ctx context.Context, client *http.Client, limiter *rateLimiter, method, rawurl string) (int, error) {
+	if limiter != nil {
+		if err := limiter.wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawurl, nil)
+	if err != nil {
+		return 0, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,136 @@
+package httputils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// minBackoff the floor of the waits between polls, so they never spin
+const minBackoff = 10 * time.Millisecond
+
+// maxHealthBody the largest part of the body matched by WithExpectedBody
+const maxHealthBody = 1 << 20
+
+type healthOptions struct {
+	client   *http.Client
+	statuses []int
+	body     *regexp.Regexp
+	initial  time.Duration
+	max      time.Duration
+}
+
+// HealthOption configures PollUntilHealthy
+type HealthOption func(*healthOptions)
+
+// WithHealthClient poll with client instead of http.DefaultClient
+func WithHealthClient(client *http.Client) HealthOption {
+	return func(o *healthOptions) {
+		o.client = client
+	}
+}
+
+// WithExpectedStatus accept any of the status codes instead of 200 only
+func WithExpectedStatus(codes ...int) HealthOption {
+	return func(o *healthOptions) {
+		o.statuses = codes
+	}
+}
+
+// WithExpectedBody require the response body, up to its first MiB, to match re
+func WithExpectedBody(re *regexp.Regexp) HealthOption {
+	return func(o *healthOptions) {
+		o.body = re
+	}
+}
+
+// WithBackoff wait initial after the first failed poll, doubling up to max,
+// 500ms and 10s by default. Waits shorter than 10ms are raised to it.
+func WithBackoff(initial, max time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		o.initial = initial
+		o.max = max
+	}
+}
+
+// PollUntilHealthy poll rawurl with exponential backoff until it answers with an
+// expected status and body, or ctx is done. In the latter case the returned
+// error wraps the context error and tells why the last poll failed.
+func PollUntilHealthy(ctx context.Context, rawurl string, opts ...HealthOption) error {
+	o := healthOptions{
+		client:   http.DefaultClient,
+		statuses: []int{http.StatusOK},
+		initial:  500 * time.Millisecond,
+		max:      10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.initial < minBackoff {
+		o.initial = minBackoff
+	}
+	if o.max < o.initial {
+		o.max = o.initial
+	}
+
+	wait := o.initial
+	for {
+		last := pollOnce(ctx, o, rawurl)
+		if last == nil {
+			return nil
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("%s is not healthy: %w (last poll: %v)", rawurl, ctx.Err(), last)
+		case <-t.C:
+		}
+
+		wait *= 2
+		if wait > o.max {
+			wait = o.max
+		}
+	}
+}
+
+// pollOnce return why rawurl is not healthy, nil if it is
+func pollOnce(ctx context.Context, o healthOptions, rawurl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	ok := false
+	for _, v := range o.statuses {
+		if resp.StatusCode == v {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if o.body != nil {
+		b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHealthBody))
+		if err != nil {
+			return err
+		}
+		if !o.body.Match(b) {
+			return fmt.Errorf("body does not match %s", o.body)
+		}
+	}
+	return nil
+}
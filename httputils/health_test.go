@@ -0,0 +1,44 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollUntilHealthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		healthy bool
+	}{
+		{"ready", "ready", true},
+		{"beyond the body limit", strings.Repeat("x", maxHealthBody) + "ready", false},
+	}
+	for _, tc := range tests {
+		var polls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&polls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(tc.body))
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		// a zero backoff is floored instead of spinning
+		err := PollUntilHealthy(ctx, ts.URL, WithBackoff(0, 0), WithExpectedBody(regexp.MustCompile("ready")))
+		cancel()
+		ts.Close()
+
+		n := atomic.LoadInt32(&polls)
+		if (err == nil) != tc.healthy || n < 3 || n > 100 {
+			t.Errorf("[httputils]: PollUntilHealthy %s test failed, expecting healthy %v after a few polls, got %d polls, err %v", tc.name, tc.healthy, n, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrPartChanged a part of OpenMulti changed on the server while resuming it
+var ErrPartChanged = errors.New("Part changed on the server while resuming")
+
+type multiOptions struct {
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// MultiOption configures OpenMulti
+type MultiOption func(*multiOptions)
+
+// WithMultiClient download with client instead of http.DefaultClient
+func WithMultiClient(client *http.Client) MultiOption {
+	return func(o *multiOptions) {
+		o.client = client
+	}
+}
+
+// WithMultiRetries retry every part up to n times, waiting backoff before the
+// first retry and doubling it after every other, 3 times and 1s by default
+func WithMultiRetries(n int, backoff time.Duration) MultiOption {
+	return func(o *multiOptions) {
+		o.retries = n
+		o.backoff = backoff
+	}
+}
+
+// OpenMulti return a reader presenting the resources at urls, downloaded one
+// after the other, as one continuous stream, eg: the parts of a split archive.
+// A part failing on a network error or a 5xx status is resumed where it was
+// interrupted, with a Range request, up to the retry limit. The request carries
+// If-Range with the ETag or Last-Modified of the part, a part changed in the
+// meantime fails with ErrPartChanged. The first part is requested before
+// returning, so that a wrong url fails early.
+func OpenMulti(ctx context.Context, urls []string, opts ...MultiOption) (io.ReadCloser, error) {
+	o := multiOptions{client: http.DefaultClient, retries: 3, backoff: time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r := &multiReader{ctx: ctx, o: o, urls: urls}
+	if len(urls) > 0 {
+		if err := r.open(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+type multiReader struct {
+	ctx  context.Context
+	o    multiOptions
+	urls []string
+	// part the index of the current part in urls, off the bytes read from it
+	part  int
+	off   int64
+	tries int
+	// validator the ETag or Last-Modified of the current part
+	validator string
+	body      io.ReadCloser
+	err       error
+}
+
+func (r *multiReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.part >= len(r.urls) {
+			return 0, io.EOF
+		}
+		if r.body == nil {
+			if err := r.open(); err != nil {
+				r.err = err
+				break
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.off += int64(n)
+		switch {
+		case err == io.EOF:
+			r.body.Close()
+			r.body = nil
+			r.part++
+			r.off = 0
+			r.tries = 0
+			r.validator = ""
+		case err != nil:
+			r.body.Close()
+			r.body = nil
+			if !r.retry(err) {
+				r.err = fmt.Errorf("download %s: %w", r.urls[r.part], err)
+			}
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, r.err
+}
+
+func (r *multiReader) Close() error {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	if r.err == nil {
+		r.err = errors.New("read of closed multi url reader")
+	}
+	return nil
+}
+
+// open request the current part from r.off, retrying on transient errors
+func (r *multiReader) open() error {
+	for {
+		body, err := r.get()
+		if err == nil {
+			r.body = body
+			return nil
+		}
+		if !r.retry(err) {
+			return err
+		}
+	}
+}
+
+// retry whether err is transient and the current part has retries left, in
+// which case it waits for the backoff
+func (r *multiReader) retry(err error) bool {
+	var se *statusError
+	if r.ctx.Err() != nil || r.tries >= r.o.retries || (errors.As(err, &se) && se.code < 500) || errors.Is(err, ErrPartChanged) {
+		return false
+	}
+	t := time.NewTimer(r.o.backoff << r.tries)
+	defer t.Stop()
+	r.tries++
+	select {
+	case <-r.ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// get the body of the current part from r.off
+func (r *multiReader) get() (io.ReadCloser, error) {
+	u := r.urls[r.part]
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.off > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.off))
+		if len(r.validator) > 0 {
+			req.Header.Set("If-Range", r.validator)
+		}
+	}
+
+	resp, err := r.o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case r.off > 0 && resp.StatusCode == http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != r.off {
+			resp.Body.Close()
+			return nil, fmt.Errorf("download %s: %w, got range %q from offset %d", u, ErrPartChanged, resp.Header.Get("Content-Range"), r.off)
+		}
+	case resp.StatusCode == http.StatusOK:
+		v := validator(resp)
+		if r.off == 0 {
+			r.validator = v
+		} else if len(r.validator) > 0 && v != r.validator {
+			resp.Body.Close()
+			return nil, fmt.Errorf("download %s: %w", u, ErrPartChanged)
+		}
+		// no range support, skip what was already read
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, r.off); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	default:
+		resp.Body.Close()
+		return nil, &statusError{u, resp.Status, resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// validator the strong ETag of resp, or else its Last-Modified, for If-Range
+func validator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); len(etag) > 0 && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// contentRangeStart the first byte of a "bytes start-end/size" Content-Range
+func contentRangeStart(s string) (int64, bool) {
+	var start, end int64
+	var size string
+	if _, err := fmt.Sscanf(s, "bytes %d-%d/%s", &start, &end, &size); err != nil || end < start {
+		return 0, false
+	}
+	return start, true
+}
+
+// statusError a http response with an unexpected status
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("download %s: %s", e.url, e.status)
+}
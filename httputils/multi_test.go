@@ -0,0 +1,63 @@
+package httputils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestOpenMultiResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	tests := []struct {
+		name    string
+		changed bool
+	}{
+		{"unchanged", false},
+		{"changed", true},
+	}
+	for _, tc := range tests {
+		var requests int
+		var ifRange string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				// cut the connection halfway through
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.Write(content[:50])
+				return
+			}
+			ifRange = r.Header.Get("If-Range")
+			etag := `"v1"`
+			if tc.changed {
+				etag = `"v2"`
+			}
+			w.Header().Set("ETag", etag)
+			http.ServeContent(w, r, "a", time.Time{}, bytes.NewReader(content))
+		}))
+
+		r, err := OpenMulti(context.Background(), []string{ts.URL}, WithMultiClient(ts.Client()), WithMultiRetries(1, time.Millisecond))
+		if err != nil {
+			t.Fatalf("[httputils]: OpenMulti %s test failed with %s", tc.name, err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		ts.Close()
+
+		if tc.changed {
+			if !errors.Is(err, ErrPartChanged) {
+				t.Errorf("[httputils]: OpenMulti %s test failed, expecting ErrPartChanged, got %v", tc.name, err)
+			}
+			continue
+		}
+		if err != nil || !bytes.Equal(b, content) || ifRange != `"v1"` {
+			t.Errorf("[httputils]: OpenMulti %s test failed, expecting the content resumed with If-Range \"v1\", got %q with If-Range %q, err %v", tc.name, b, ifRange, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package httputils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrNotSlicePage = errors.New("Page must be decoded into a pointer to slice")
+
+// PageStrategy return the url of the page after the one fetched with req,
+// or "" if it was the last one. page is the decoded body of resp.
+type PageStrategy func(req *http.Request, resp *http.Response, page interface{}) (string, error)
+
+// LinkHeader follow the rel="next" link of the RFC 5988 Link header, as used by GitHub and Gitea
+func LinkHeader() PageStrategy {
+	return func(req *http.Request, resp *http.Response, page interface{}) (string, error) {
+		next := parseLinkHeader(resp.Header.Values("Link"))["next"]
+		if len(next) == 0 {
+			return "", nil
+		}
+		u, err := req.URL.Parse(next)
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+}
+
+// QueryPage increase the page number in the query parameter param, starting
+// from the one in the first url or 1. The pages must be decoded into slices,
+// an empty page ends the iteration.
+func QueryPage(param string) PageStrategy {
+	return func(req *http.Request, resp *http.Response, page interface{}) (string, error) {
+		n, err := pageLen(page)
+		if err != nil || n == 0 {
+			return "", err
+		}
+		q := req.URL.Query()
+		cur := 1
+		if v := q.Get(param); len(v) > 0 {
+			cur, err = strconv.Atoi(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid page %q: %w", v, err)
+			}
+		}
+		q.Set(param, strconv.Itoa(cur+1))
+		u := *req.URL
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+}
+
+// QueryOffset increase the offset in the query parameter param by the number of
+// elements received. The pages must be decoded into slices, a page shorter than
+// limit ends the iteration, a non-positive limit only stops at an empty page.
+func QueryOffset(param string, limit int) PageStrategy {
+	return func(req *http.Request, resp *http.Response, page interface{}) (string, error) {
+		n, err := pageLen(page)
+		if err != nil || n == 0 || (limit > 0 && n < limit) {
+			return "", err
+		}
+		q := req.URL.Query()
+		cur := 0
+		if v := q.Get(param); len(v) > 0 {
+			cur, err = strconv.Atoi(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid offset %q: %w", v, err)
+			}
+		}
+		q.Set(param, strconv.Itoa(cur+n))
+		u := *req.URL
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+}
+
+// Paginator iterate over the pages of a paginated JSON API:
+//
+//	p := NewPaginator(ctx, client, "https://api.github.com/users/x/repos", LinkHeader())
+//	for {
+//		var repos []Repo
+//		if !p.Next(&repos) {
+//			break
+//		}
+//		...
+//	}
+//	if p.Err() != nil {
+//		...
+//	}
+type Paginator struct {
+	ctx      context.Context
+	client   *http.Client
+	next     string
+	strategy PageStrategy
+	err      error
+	// Header is sent with every request
+	Header http.Header
+}
+
+// NewPaginator return a Paginator starting at rawurl
+func NewPaginator(ctx context.Context, client *http.Client, rawurl string, strategy PageStrategy) *Paginator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Paginator{ctx: ctx, client: client, next: rawurl, strategy: strategy, Header: make(http.Header)}
+}
+
+// Next fetch the next page and decode it into v. It returns false when there
+// are no more pages, an empty page is reached or an error happened, see Err.
+func (p *Paginator) Next(v interface{}) bool {
+	if p.err != nil || len(p.next) == 0 {
+		return false
+	}
+	if p.err = p.ctx.Err(); p.err != nil {
+		return false
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, p.next, nil)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	for k, vals := range p.Header {
+		req.Header[k] = vals
+	}
+	if len(req.Header.Get("Accept")) == 0 {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		p.err = fmt.Errorf("get %s: %s", p.next, resp.Status)
+		return false
+	}
+
+	if p.err = json.NewDecoder(resp.Body).Decode(v); p.err != nil {
+		return false
+	}
+
+	p.next, p.err = p.strategy(req, resp, v)
+	if p.err != nil {
+		return false
+	}
+
+	if n, err := pageLen(v); err == nil && n == 0 {
+		p.next = ""
+		return false
+	}
+	return true
+}
+
+// Err the error that stopped the iteration, if any
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+// pageLen the number of elements of a page decoded into a pointer to slice
+func pageLen(page interface{}) (int, error) {
+	v := reflect.ValueOf(page)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return 0, ErrNotSlicePage
+	}
+	return v.Len(), nil
+}
+
+// parseLinkHeader parse `<url>; rel="next", <url>; rel="last"` into rel to url
+func parseLinkHeader(values []string) map[string]string {
+	m := make(map[string]string)
+	for _, header := range values {
+		for _, link := range splitOutside(header, ',') {
+			parts := splitOutside(link, ';')
+			u := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(u, "<") || !strings.HasSuffix(u, ">") {
+				continue
+			}
+			u = u[1 : len(u)-1]
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || strings.ToLower(kv[0]) != "rel" {
+					continue
+				}
+				// rel can hold several space separated values
+				for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					m[strings.ToLower(rel)] = u
+				}
+			}
+		}
+	}
+	return m
+}
+
+// splitOutside split s around sep, except within <...> and quoted strings,
+// where urls and titles can hold it
+func splitOutside(s string, sep byte) []string {
+	var parts []string
+	var quoted, bracketed bool
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"' && !bracketed:
+			quoted = !quoted
+		case c == '<' && !quoted:
+			bracketed = true
+		case c == '>' && !quoted:
+			bracketed = false
+		case c == sep && !quoted && !bracketed:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
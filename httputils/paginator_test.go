@@ -0,0 +1,27 @@
+package httputils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	tests := []struct {
+		values  []string
+		correct map[string]string
+	}{
+		{[]string{`<https://a/?page=2>; rel="next", <https://a/?page=9>; rel="last"`},
+			map[string]string{"next": "https://a/?page=2", "last": "https://a/?page=9"}},
+		{[]string{`<https://a/?ids=1,2;v=3>; rel="next"`},
+			map[string]string{"next": "https://a/?ids=1,2;v=3"}},
+		{[]string{`<https://a/2>; title="one, two; three"; rel="next prev"`},
+			map[string]string{"next": "https://a/2", "prev": "https://a/2"}},
+		{[]string{`<https://a/2>; rel=next`, `<https://a/1>; rel="first"`},
+			map[string]string{"next": "https://a/2", "first": "https://a/1"}},
+	}
+	for _, tc := range tests {
+		if m := parseLinkHeader(tc.values); !reflect.DeepEqual(m, tc.correct) {
+			t.Errorf("[httputils]: parseLinkHeader test failed, expecting %v, got %v", tc.correct, m)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package httputils
+
+import "net/http"
+
+// OriginPolicy decides what happens to the Origin header when a redirect
+// leads to another host
+type OriginPolicy int
+
+const (
+	// OriginKeep send the Origin header of the first request, the default
+	OriginKeep OriginPolicy = iota
+	// OriginStrip drop the Origin header
+	OriginStrip
+	// OriginNull send "Origin: null", like browsers do for opaque origins
+	OriginNull
+)
+
+// WithReferer send "Referer: referer" with every request, redirected ones
+// included, instead of the url redirected from
+func WithReferer(referer string) ClientOption {
+	return func(o *clientOptions) {
+		o.referer = referer
+	}
+}
+
+// WithoutReferer never send a Referer header, not even after redirects
+func WithoutReferer() ClientOption {
+	return func(o *clientOptions) {
+		o.noReferer = true
+	}
+}
+
+// WithOrigin send "Origin: origin" with every request, subject to the OriginPolicy
+func WithOrigin(origin string) ClientOption {
+	return func(o *clientOptions) {
+		o.origin = origin
+	}
+}
+
+// WithOriginPolicy handle the Origin header of requests redirected to another
+// host than the first one with p
+func WithOriginPolicy(p OriginPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.originPolicy = p
+	}
+}
+
+// setRefererOrigin apply the Referer and Origin options of o to req
+func setRefererOrigin(req *http.Request, o clientOptions) {
+	switch {
+	case o.noReferer:
+		req.Header.Del("Referer")
+	case len(o.referer) > 0:
+		req.Header.Set("Referer", o.referer)
+	}
+
+	if len(o.origin) > 0 && len(req.Header.Get("Origin")) == 0 {
+		req.Header.Set("Origin", o.origin)
+	}
+
+	// every hop after leaving the first host is cross-origin, even B to B
+	if len(req.Header.Get("Origin")) == 0 || sameHostAsFirst(req) {
+		return
+	}
+	switch o.originPolicy {
+	case OriginStrip:
+		req.Header.Del("Origin")
+	case OriginNull:
+		req.Header.Set("Origin", "null")
+	}
+}
@@ -0,0 +1,122 @@
+package httputils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WithSegments download with n parallel range requests into a preallocated
+// file, see DownloadTo
+func WithSegments(n int) DownloadOption {
+	return func(o *downloadOptions) {
+		o.segments = n
+	}
+}
+
+// DownloadTo download rawurl into w with up to segments parallel range
+// requests, each writing its part at its offset as soon as it arrives, in
+// any order. If w is an *os.File it is preallocated first (fallocate on
+// Linux), which avoids fragmenting large files. Servers not answering HEAD,
+// not telling the size or not accepting ranges get a single plain request,
+// a preallocated file is then truncated to what it got. It returns the
+// number of bytes written.
+func DownloadTo(client *http.Client, rawurl string, w io.WriterAt, segments int) (int64, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Head(rawurl)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	// some servers refuse HEAD but serve GET
+	size := int64(-1)
+	var ranges bool
+	if resp.StatusCode == http.StatusOK {
+		size = resp.ContentLength
+		ranges = strings.Contains(resp.Header.Get("Accept-Ranges"), "bytes")
+	}
+
+	f, preallocated := w.(*os.File)
+	preallocated = preallocated && size > 0
+	if preallocated {
+		if err := preallocate(f, size); err != nil {
+			return 0, err
+		}
+	}
+
+	// plain download rawurl with a single request
+	plain := func() (int64, error) {
+		_, n, err := fetchRange(client, rawurl, -1, -1, &offsetWriter{w: w})
+		if err == nil && preallocated && n < size {
+			err = f.Truncate(n)
+		}
+		return n, err
+	}
+
+	if !ranges || size <= 0 || segments < 2 {
+		return plain()
+	}
+
+	if int64(segments) > size {
+		segments = int(size)
+	}
+	part := size / int64(segments)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int64
+		full  bool
+		first error
+	)
+	for i := 0; i < segments; i++ {
+		start := int64(i) * part
+		end := start + part - 1
+		if i == segments-1 {
+			end = size - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			whole, n, err := fetchRange(client, rawurl, start, end, &offsetWriter{w: w, off: start})
+			if err == nil && !whole && n != end-start+1 {
+				err = fmt.Errorf("download %s: bytes %d-%d: %w", rawurl, start, end, io.ErrUnexpectedEOF)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			total += n
+			full = full || whole
+			if err != nil && first == nil {
+				first = err
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return total, first
+	}
+	// the server ignored the ranges after all
+	if full {
+		return plain()
+	}
+	return total, nil
+}
+
+// offsetWriter an io.Writer writing sequentially into an io.WriterAt from off
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	return n, err
+}
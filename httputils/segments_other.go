@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package httputils
+
+import "os"
+
+// preallocate set the size of f, the file system decides whether blocks are reserved
+func preallocate(f *os.File, size int64) error {
+	return f.Truncate(size)
+}
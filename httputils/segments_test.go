@@ -0,0 +1,54 @@
+package httputils
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDownloadTo(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		correct []byte
+	}{
+		{"ranges", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "a", time.Time{}, bytes.NewReader(content))
+		}, content},
+		{"no HEAD", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Write(content)
+		}, content},
+		{"ranges ignored and shorter body", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.Header().Set("Accept-Ranges", "bytes")
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				return
+			}
+			w.Write(content[:50])
+		}, content[:50]},
+	}
+	for _, tc := range tests {
+		ts := httptest.NewServer(tc.handler)
+		f, err := os.Create(filepath.Join(t.TempDir(), "a"))
+		if err != nil {
+			t.Fatalf("[httputils]: DownloadTo test failed with %s", err)
+		}
+		n, err := DownloadTo(ts.Client(), ts.URL, f, 4)
+		f.Close()
+		ts.Close()
+		b, _ := os.ReadFile(f.Name())
+		if err != nil || n != int64(len(tc.correct)) || !bytes.Equal(b, tc.correct) {
+			t.Errorf("[httputils]: DownloadTo %s test failed, expecting %q, got %q, %d bytes, err %v", tc.name, tc.correct, b, n, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package httputils
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WithSystemProxy use the proxy configured in the desktop environment: GNOME
+// (gsettings), KDE (kioslaverc) or Windows (WinHTTP), so that users of
+// graphical sessions don't have to export "http(s)?_proxy". Without such a
+// configuration the environment variables are used. WithProxy takes precedence.
+func WithSystemProxy() ClientOption {
+	return func(o *clientOptions) {
+		o.systemProxy = true
+	}
+}
+
+// systemProxy a manual proxy configuration of the desktop environment
+type systemProxy struct {
+	http    *url.URL
+	https   *url.URL
+	noProxy []string
+}
+
+// detectSystemProxy the proxy configuration of the desktop environment, nil if there is none
+func detectSystemProxy() *systemProxy {
+	if runtime.GOOS == "windows" {
+		return winHTTPProxy()
+	}
+	if p := gnomeProxy(); p != nil {
+		return p
+	}
+	return kdeProxy()
+}
+
+// proxy the proxy for req, like http.ProxyFromEnvironment does: localhost and
+// loopback addresses are never proxied
+func (p *systemProxy) proxy(req *http.Request) (*url.URL, error) {
+	host := req.URL.Hostname()
+	if host == "localhost" {
+		return nil, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil, nil
+	}
+	for _, v := range p.noProxy {
+		// <local> is Windows for the names without a dot
+		if v == "<local>" {
+			if !strings.Contains(host, ".") {
+				return nil, nil
+			}
+			continue
+		}
+		v = strings.TrimPrefix(strings.TrimPrefix(v, "*"), ".")
+		if len(v) == 0 {
+			continue
+		}
+		if host == v || strings.HasSuffix(host, "."+v) {
+			return nil, nil
+		}
+		if _, n, err := net.ParseCIDR(v); err == nil {
+			if ip := net.ParseIP(host); ip != nil && n.Contains(ip) {
+				return nil, nil
+			}
+		}
+	}
+	if req.URL.Scheme == "https" && p.https != nil {
+		return p.https, nil
+	}
+	return p.http, nil
+}
+
+// parseProxy turn host and port into a proxy url, host may already be a url
+func parseProxy(host, port string) *url.URL {
+	host = strings.TrimSpace(host)
+	if len(host) == 0 {
+		return nil
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil
+	}
+	if len(u.Port()) == 0 && len(port) > 0 && port != "0" {
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return u
+}
+
+func gnomeProxy() *systemProxy {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return nil
+	}
+	out, err := exec.Command("gsettings", "list-recursively", "org.gnome.system.proxy").Output()
+	if err != nil {
+		return nil
+	}
+	return parseGsettings(string(out))
+}
+
+// parseGsettings parse the output of "gsettings list-recursively
+// org.gnome.system.proxy", lines like "org.gnome.system.proxy.http host 'proxy'"
+func parseGsettings(out string) *systemProxy {
+	settings := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		settings[fields[0]+" "+fields[1]] = strings.Trim(fields[2], "'")
+	}
+	if settings["org.gnome.system.proxy mode"] != "manual" {
+		return nil
+	}
+
+	p := &systemProxy{
+		http:  parseProxy(settings["org.gnome.system.proxy.http host"], settings["org.gnome.system.proxy.http port"]),
+		https: parseProxy(settings["org.gnome.system.proxy.https host"], settings["org.gnome.system.proxy.https port"]),
+	}
+	// ['localhost', '127.0.0.0/8'], or "@as []" when empty
+	hosts := strings.TrimPrefix(settings["org.gnome.system.proxy ignore-hosts"], "@as ")
+	for _, v := range strings.Split(strings.Trim(hosts, "[]"), ",") {
+		if v = strings.Trim(strings.TrimSpace(v), "'"); len(v) > 0 {
+			p.noProxy = append(p.noProxy, v)
+		}
+	}
+	if p.http == nil && p.https == nil {
+		return nil
+	}
+	return p
+}
+
+func kdeProxy() *systemProxy {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if len(dir) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	f, err := os.Open(filepath.Join(dir, "kioslaverc"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	return parseKioslaverc(f)
+}
+
+// parseKioslaverc parse the [Proxy Settings] of the KDE kioslaverc file
+func parseKioslaverc(r io.Reader) *systemProxy {
+	// KDE writes "http://host:port" or "http://host port"
+	kdeURL := func(v string) *url.URL {
+		fields := strings.Fields(v)
+		if len(fields) == 2 {
+			return parseProxy(fields[0], fields[1])
+		}
+		return parseProxy(v, "")
+	}
+
+	var p systemProxy
+	manual := false
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		i := strings.Index(line, "=")
+		if section != "[Proxy Settings]" || i < 0 {
+			continue
+		}
+		k, v := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		switch k {
+		case "ProxyType":
+			// 1 is the manual configuration
+			n, _ := strconv.Atoi(v)
+			manual = n == 1
+		case "httpProxy":
+			p.http = kdeURL(v)
+		case "httpsProxy":
+			p.https = kdeURL(v)
+		case "NoProxyFor":
+			p.noProxy = nil
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); len(s) > 0 {
+					p.noProxy = append(p.noProxy, s)
+				}
+			}
+		}
+	}
+
+	if !manual || (p.http == nil && p.https == nil) {
+		return nil
+	}
+	return &p
+}
+
+func winHTTPProxy() *systemProxy {
+	out, err := exec.Command("netsh", "winhttp", "show", "proxy").Output()
+	if err != nil {
+		return nil
+	}
+	return parseNetsh(string(out))
+}
+
+// parseNetsh parse the output of "netsh winhttp show proxy"
+func parseNetsh(out string) *systemProxy {
+	// Proxy Server(s) :  proxy:8080
+	// Bypass List     :  *.local;<local>
+	var p systemProxy
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		k, v := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		switch k {
+		case "Proxy Server(s)":
+			// either one proxy for everything or "http=host:port;https=host:port"
+			for _, s := range strings.Split(v, ";") {
+				if j := strings.Index(s, "="); j > 0 {
+					switch s[:j] {
+					case "http":
+						p.http = parseProxy(s[j+1:], "")
+					case "https":
+						p.https = parseProxy(s[j+1:], "")
+					}
+				} else {
+					p.http = parseProxy(s, "")
+				}
+			}
+		case "Bypass List":
+			for _, s := range strings.Split(v, ";") {
+				if s = strings.TrimSpace(s); len(s) > 0 {
+					p.noProxy = append(p.noProxy, s)
+				}
+			}
+		}
+	}
+
+	if p.http == nil && p.https == nil {
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,79 @@
+package httputils
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSystemProxyParsers(t *testing.T) {
+	gsettings := `org.gnome.system.proxy.http host 'proxy.example.com'
+org.gnome.system.proxy.http port 3128
+org.gnome.system.proxy.https host ''
+org.gnome.system.proxy.https port 0
+org.gnome.system.proxy mode 'manual'
+org.gnome.system.proxy ignore-hosts ['localhost', '10.0.0.0/8']
+`
+	kioslaverc := `[Proxy Settings]
+ProxyType=1
+httpProxy=http://proxy.example.com 3128
+NoProxyFor=.internal, 10.0.0.0/8 ,
+`
+	netsh := "\r\nCurrent WinHTTP proxy settings:\r\n\r\n    Proxy Server(s) :  http=proxy.example.com:3128;https=secure.example.com:443\r\n    Bypass List     :  *.internal;<local>\r\n"
+
+	tests := []struct {
+		name    string
+		p       *systemProxy
+		correct *systemProxy
+	}{
+		{"gsettings", parseGsettings(gsettings), &systemProxy{
+			http:    &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+			noProxy: []string{"localhost", "10.0.0.0/8"},
+		}},
+		{"gsettings none", parseGsettings(strings.Replace(gsettings, "'manual'", "'none'", 1)), nil},
+		{"gsettings empty ignore-hosts", parseGsettings(strings.Replace(gsettings, "['localhost', '10.0.0.0/8']", "@as []", 1)), &systemProxy{
+			http: &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+		}},
+		{"kioslaverc", parseKioslaverc(strings.NewReader(kioslaverc)), &systemProxy{
+			http:    &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+			noProxy: []string{".internal", "10.0.0.0/8"},
+		}},
+		{"kioslaverc no proxy", parseKioslaverc(strings.NewReader(strings.Replace(kioslaverc, "ProxyType=1", "ProxyType=0", 1))), nil},
+		{"netsh", parseNetsh(netsh), &systemProxy{
+			http:    &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+			https:   &url.URL{Scheme: "http", Host: "secure.example.com:443"},
+			noProxy: []string{"*.internal", "<local>"},
+		}},
+		{"netsh direct", parseNetsh("    Direct access (no proxy server).\r\n"), nil},
+	}
+	for _, tc := range tests {
+		if !reflect.DeepEqual(tc.p, tc.correct) {
+			t.Errorf("[httputils]: system proxy %s test failed, expecting %+v, got %+v", tc.name, tc.correct, tc.p)
+		}
+	}
+}
+
+func TestSystemProxyBypass(t *testing.T) {
+	p := &systemProxy{
+		http:    &url.URL{Scheme: "http", Host: "proxy:3128"},
+		noProxy: []string{".internal", "10.0.0.0/8", "<local>"},
+	}
+	for rawurl, bypass := range map[string]bool{
+		"http://localhost:8080/":   true,
+		"http://127.0.0.1/":        true,
+		"http://[::1]/":            true,
+		"http://a.internal/":       true,
+		"http://10.1.2.3/":         true,
+		"http://intranet/":         true,
+		"http://example.com/":      false,
+		"http://internal.example/": false,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, rawurl, nil)
+		u, err := p.proxy(req)
+		if (u == nil) != bypass || err != nil {
+			t.Errorf("[httputils]: system proxy test failed for %s, expecting bypass %v, got %v, err %v", rawurl, bypass, u, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package httputils
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WithInsecureHost skip TLS certificate verification for the hosts only, eg:
+// for a self-signed internal mirror, while the others are still verified.
+// A port in host is ignored.
+func WithInsecureHost(hosts ...string) ClientOption {
+	return func(o *clientOptions) {
+		if o.insecureHosts == nil {
+			o.insecureHosts = make(map[string]bool)
+		}
+		for _, h := range hosts {
+			o.insecureHosts[hostOnly(h)] = true
+		}
+	}
+}
+
+// WithHostCAFile trust the PEM encoded certificates in files, in addition to
+// the system pool, for host only
+func WithHostCAFile(host string, files ...string) ClientOption {
+	return func(o *clientOptions) {
+		if o.hostCAFiles == nil {
+			o.hostCAFiles = make(map[string][]string)
+		}
+		h := hostOnly(host)
+		o.hostCAFiles[h] = append(o.hostCAFiles[h], files...)
+	}
+}
+
+// perHostTLS return a round tripper using clones of transport with the TLS
+// exceptions of o for the hosts concerned, transport for the others. Go only
+// supports these settings per transport.
+func perHostTLS(transport *http.Transport, o clientOptions) (http.RoundTripper, error) {
+	if o.insecure || (len(o.insecureHosts) == 0 && len(o.hostCAFiles) == 0) {
+		return transport, nil
+	}
+
+	hosts := make(map[string]*http.Transport)
+	for h := range o.hostCAFiles {
+		pool, err := certPool(o.hostCAFiles[h])
+		if err != nil {
+			return nil, err
+		}
+		t := transport.Clone()
+		t.TLSClientConfig.RootCAs = pool
+		hosts[h] = t
+	}
+	for h := range o.insecureHosts {
+		t, ok := hosts[h]
+		if !ok {
+			t = transport.Clone()
+			hosts[h] = t
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if t, ok := hosts[strings.ToLower(req.URL.Hostname())]; ok {
+			return t.RoundTrip(req)
+		}
+		return transport.RoundTrip(req)
+	}), nil
+}
+
+// certPool the system pool with the PEM encoded certificates in files added
+func certPool(files []string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", f)
+		}
+	}
+	return pool, nil
+}
+
+// hostOnly strip the port from host
+func hostOnly(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
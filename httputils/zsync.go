@@ -0,0 +1,401 @@
+package httputils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/md4"
+)
+
+var ErrInvalidZsync = errors.New("Invalid zsync control file")
+
+// DeltaStats what DownloadDeltas did
+type DeltaStats struct {
+	Blocks int
+	// Reused the number of blocks copied from the old file
+	Reused int
+	// Downloaded the number of bytes fetched from the remote
+	Downloaded int64
+}
+
+// zsyncControl the parsed .zsync control file
+type zsyncControl struct {
+	url       string
+	blockSize int
+	length    int64
+	// seqMatches the number of consecutive blocks that must match together
+	seqMatches int
+	rsumBytes  int
+	sumBytes   int
+	sha1       string
+	rsums      []uint32
+	sums       [][]byte
+}
+
+// DownloadDeltas update old to the file described by the zsync control file at
+// zsyncURL and write the result to dest. Blocks already present in old are
+// reused and only the changed ones are fetched with range requests. The result
+// is verified against the SHA-1 of the control file. old and dest can be the same.
+func DownloadDeltas(client *http.Client, zsyncURL, old, dest string) (DeltaStats, error) {
+	var stats DeltaStats
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	ctl, err := fetchZsync(client, zsyncURL)
+	if err != nil {
+		return stats, err
+	}
+	stats.Blocks = len(ctl.rsums)
+
+	found, err := matchBlocks(ctl, old)
+	if err != nil && !os.IsNotExist(err) {
+		return stats, err
+	}
+
+	f, err := partFile(dest)
+	if err != nil {
+		return stats, err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	h := sha1.New()
+	w := io.MultiWriter(f, h)
+
+	var of *os.File
+	if len(found) > 0 {
+		of, err = os.Open(old)
+		if err != nil {
+			return stats, err
+		}
+		defer of.Close()
+	}
+
+	bs := int64(ctl.blockSize)
+	for i := 0; i < len(ctl.rsums); {
+		start := int64(i) * bs
+
+		if off, ok := found[i]; ok {
+			_, err = io.Copy(w, io.NewSectionReader(of, off, minInt64(bs, ctl.length-start)))
+			if err != nil {
+				return stats, err
+			}
+			stats.Reused++
+			i++
+			continue
+		}
+
+		// fetch consecutive missing blocks with one request
+		j := i + 1
+		for ; j < len(ctl.rsums); j++ {
+			if _, ok := found[j]; ok {
+				break
+			}
+		}
+		end := minInt64(int64(j)*bs, ctl.length)
+
+		full, n, err := fetchRange(client, ctl.url, start, end-1, w)
+		stats.Downloaded += n
+		if err != nil {
+			return stats, err
+		}
+		if full {
+			// the server ignored the range and sent the whole file
+			if err := f.Truncate(0); err != nil {
+				return stats, err
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return stats, err
+			}
+			h.Reset()
+			stats.Reused = 0
+			_, n, err = fetchRange(client, ctl.url, -1, -1, w)
+			stats.Downloaded = n
+			if err != nil {
+				return stats, err
+			}
+			break
+		}
+		i = j
+	}
+
+	if len(ctl.sha1) > 0 && hex.EncodeToString(h.Sum(nil)) != ctl.sha1 {
+		return stats, fmt.Errorf("%w: %s", ErrChecksumMismatch, ctl.url)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return stats, err
+	}
+	return stats, os.Rename(tmp, dest)
+}
+
+// fetchRange write bytes start to end of rawurl to w, a negative start fetches the whole file.
+// full is true if the server answered a range request with the whole file, nothing is written then.
+func fetchRange(client *http.Client, rawurl string, start, end int64, w io.Writer) (full bool, n int64, err error) {
+	req, err := http.NewRequest(http.MethodGet, rawurl, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	if start >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case start >= 0 && resp.StatusCode == http.StatusOK:
+		return true, 0, nil
+	case start >= 0 && resp.StatusCode != http.StatusPartialContent,
+		start < 0 && resp.StatusCode != http.StatusOK:
+		return false, 0, fmt.Errorf("download %s: %s", rawurl, resp.Status)
+	}
+
+	n, err = io.Copy(w, resp.Body)
+	return false, n, err
+}
+
+// fetchZsync download and parse the zsync control file
+func fetchZsync(client *http.Client, zsyncURL string) (*zsyncControl, error) {
+	resp, err := client.Get(zsyncURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", zsyncURL, resp.Status)
+	}
+
+	ctl, err := parseZsync(bufio.NewReader(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(zsyncURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := base.Parse(ctl.url)
+	if err != nil {
+		return nil, err
+	}
+	ctl.url = u.String()
+
+	return ctl, nil
+}
+
+func parseZsync(r *bufio.Reader) (*zsyncControl, error) {
+	ctl := &zsyncControl{seqMatches: 1, rsumBytes: 4, sumBytes: 16}
+
+	// the header ends with an empty line, the binary block checksums follow
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, ErrInvalidZsync
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			break
+		}
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			return nil, ErrInvalidZsync
+		}
+		k, v := line[:i], line[i+2:]
+		switch k {
+		case "URL":
+			// the first URL is used
+			if len(ctl.url) == 0 {
+				ctl.url = v
+			}
+		case "Blocksize":
+			ctl.blockSize, err = strconv.Atoi(v)
+		case "Length":
+			ctl.length, err = strconv.ParseInt(v, 10, 64)
+		case "SHA-1":
+			ctl.sha1 = strings.ToLower(v)
+		case "Hash-Lengths":
+			// seq_matches,rsum_bytes,checksum_bytes
+			l := strings.Split(v, ",")
+			if len(l) != 3 {
+				return nil, ErrInvalidZsync
+			}
+			ctl.seqMatches, err = strconv.Atoi(l[0])
+			if err == nil {
+				ctl.rsumBytes, err = strconv.Atoi(l[1])
+			}
+			if err == nil {
+				ctl.sumBytes, err = strconv.Atoi(l[2])
+			}
+		}
+		if err != nil {
+			return nil, ErrInvalidZsync
+		}
+	}
+
+	if ctl.blockSize <= 0 || ctl.length < 0 || len(ctl.url) == 0 || ctl.seqMatches < 1 || ctl.seqMatches > 2 ||
+		ctl.rsumBytes < 1 || ctl.rsumBytes > 4 || ctl.sumBytes < 1 || ctl.sumBytes > md4.Size {
+		return nil, ErrInvalidZsync
+	}
+
+	n := int((ctl.length + int64(ctl.blockSize) - 1) / int64(ctl.blockSize))
+	ctl.rsums = make([]uint32, n)
+	ctl.sums = make([][]byte, n)
+	buf := make([]byte, ctl.rsumBytes+ctl.sumBytes)
+	for i := 0; i < n; i++ {
+		_, err := io.ReadFull(r, buf)
+		if err != nil {
+			return nil, ErrInvalidZsync
+		}
+		// the last rsumBytes bytes of the big endian rsum are stored
+		var rs uint32
+		for _, b := range buf[:ctl.rsumBytes] {
+			rs = rs<<8 | uint32(b)
+		}
+		ctl.rsums[i] = rs
+		ctl.sums[i] = append([]byte(nil), buf[ctl.rsumBytes:]...)
+	}
+
+	return ctl, nil
+}
+
+// matchBlocks find the blocks of ctl in the file old, it returns block index to
+// offset in old. With seqMatches 2 the checksums are too short to be trusted
+// alone, a block only matches if the next one follows it, except the last one.
+func matchBlocks(ctl *zsyncControl, old string) (map[int]int64, error) {
+	found := make(map[int]int64)
+
+	f, err := os.Open(old)
+	if err != nil {
+		return found, err
+	}
+	defer f.Close()
+
+	index := make(map[uint32][]int)
+	for i, v := range ctl.rsums {
+		index[v] = append(index[v], i)
+	}
+
+	mask := uint32(1<<(8*uint(ctl.rsumBytes)) - 1)
+	if ctl.rsumBytes == 4 {
+		mask = ^uint32(0)
+	}
+
+	bs := ctl.blockSize
+	size := 1 << 20
+	if size < 2*bs {
+		size = 2 * bs
+	}
+	r := bufio.NewReaderSize(f, size)
+	win := make([]byte, bs)
+	ordered := make([]byte, bs)
+	next := make([]byte, bs)
+
+	strongSum := func(block []byte) []byte {
+		sum := md4.New()
+		sum.Write(block)
+		return sum.Sum(nil)[:ctl.sumBytes]
+	}
+	// last the block matched just before pos, which confirms the one at pos
+	last, lastPos := -1, int64(-1)
+	// confirmed whether block i at pos satisfies seqMatches
+	confirmed := func(i int, pos int64) bool {
+		if ctl.seqMatches < 2 || i+1 >= len(ctl.rsums) || (i == last+1 && pos == lastPos+int64(bs)) {
+			return true
+		}
+		// the block following the window, zero padded like zsync pads the last block
+		peek, _ := r.Peek(bs)
+		n := copy(next, peek)
+		for k := n; k < bs; k++ {
+			next[k] = 0
+		}
+		a, b := rsum(next)
+		return (uint32(a)<<16|uint32(b))&mask == ctl.rsums[i+1] && bytes.Equal(strongSum(next), ctl.sums[i+1])
+	}
+
+	if _, err := io.ReadFull(r, win); err != nil {
+		// shorter than a block, nothing to reuse
+		return found, nil
+	}
+	a, b := rsum(win)
+	var pos int64
+	head := 0
+
+	for {
+		if cands, ok := index[(uint32(a)<<16|uint32(b))&mask]; ok {
+			copy(ordered, win[head:])
+			copy(ordered[bs-head:], win[:head])
+			strong := strongSum(ordered)
+
+			matched := false
+			for _, i := range cands {
+				if _, ok := found[i]; ok {
+					continue
+				}
+				if bytes.Equal(strong, ctl.sums[i]) && confirmed(i, pos) {
+					found[i] = pos
+					matched = true
+					last, lastPos = i, pos
+				}
+			}
+
+			if matched {
+				// skip past the matched block
+				if _, err := io.ReadFull(r, win); err != nil {
+					return found, nil
+				}
+				pos += int64(bs)
+				head = 0
+				a, b = rsum(win)
+				continue
+			}
+		}
+
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return found, nil
+			}
+			return found, err
+		}
+		out := win[head]
+		win[head] = c
+		head = (head + 1) % bs
+		pos++
+		a += uint16(c) - uint16(out)
+		b += a - uint16(bs)*uint16(out)
+	}
+}
+
+// rsum the rsync weak checksum of a block as used by zsync
+func rsum(buf []byte) (a, b uint16) {
+	l := len(buf)
+	for i, c := range buf {
+		a += uint16(c)
+		b += uint16(l-i) * uint16(c)
+	}
+	return a, b
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
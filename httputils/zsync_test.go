@@ -0,0 +1,88 @@
+package httputils
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/md4"
+)
+
+// makeZsync build the control file of content for url
+func makeZsync(content []byte, bs, seq, rsumBytes, sumBytes int, url string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "zsync: 0.6.2\nBlocksize: %d\nLength: %d\nHash-Lengths: %d,%d,%d\nURL: %s\nSHA-1: %x\n\n",
+		bs, len(content), seq, rsumBytes, sumBytes, url, sha1.Sum(content))
+	for off := 0; off < len(content); off += bs {
+		block := make([]byte, bs)
+		copy(block, content[off:])
+		a, b := rsum(block)
+		rs := make([]byte, 4)
+		binary.BigEndian.PutUint32(rs, uint32(a)<<16|uint32(b))
+		buf.Write(rs[4-rsumBytes:])
+		h := md4.New()
+		h.Write(block)
+		buf.Write(h.Sum(nil)[:sumBytes])
+	}
+	return buf.Bytes()
+}
+
+func TestDownloadDeltas(t *testing.T) {
+	const bs = 16
+	blocks := make([][]byte, 8)
+	for i := range blocks {
+		blocks[i] = bytes.Repeat([]byte{byte('a' + i)}, bs)
+	}
+	content := bytes.Join(blocks, nil)
+	// blocks 2 and 5 changed
+	old := bytes.Join([][]byte{blocks[0], blocks[1], []byte("XXXXXXXXXXXXXXXX"), blocks[3], blocks[4], []byte("YYYYYYYYYYYYYYYY"), blocks[6], blocks[7]}, nil)
+
+	// only block 3 kept, alone it doesn't count with sequential matches
+	isolated := bytes.Join([][]byte{bytes.Repeat([]byte("Z"), 3*bs), blocks[3], bytes.Repeat([]byte("Z"), 4*bs)}, nil)
+
+	tests := []struct {
+		name     string
+		old      []byte
+		seq      int
+		ranges   bool
+		reused   int
+		download int64
+	}{
+		{"ranges", old, 1, true, 6, 2 * bs},
+		{"sequential matches", old, 2, true, 6, 2 * bs},
+		{"isolated block", isolated, 1, true, 1, 7 * bs},
+		{"isolated block with sequential matches", isolated, 2, true, 0, 8 * bs},
+		{"ranges ignored", old, 1, false, 0, int64(len(content))},
+	}
+	for _, tc := range tests {
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/a.zsync":
+				w.Write(makeZsync(content, bs, tc.seq, 2, 8, "a"))
+			case "/a":
+				if !tc.ranges {
+					w.Write(content)
+					return
+				}
+				http.ServeContent(w, r, "a", time.Time{}, bytes.NewReader(content))
+			}
+		}))
+
+		d := t.TempDir()
+		os.WriteFile(filepath.Join(d, "a"), tc.old, 0644)
+		stats, err := DownloadDeltas(ts.Client(), ts.URL+"/a.zsync", filepath.Join(d, "a"), filepath.Join(d, "a"))
+		ts.Close()
+		b, _ := os.ReadFile(filepath.Join(d, "a"))
+		if err != nil || !bytes.Equal(b, content) || stats.Reused != tc.reused || stats.Downloaded != tc.download {
+			t.Errorf("[httputils]: DownloadDeltas %s test failed, expecting %d reused blocks and %d downloaded bytes, got %+v, err %v", tc.name, tc.reused, tc.download, stats, err)
+		}
+	}
+}
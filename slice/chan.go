@@ -0,0 +1,93 @@
+package slice
+
+import (
+	"context"
+	"reflect"
+)
+
+// ToChan send every element of src to the returned channel in a new goroutine,
+// the channel has a buffer of buf and is closed after the last element.
+func ToChan(src interface{}, buf int) (<-chan interface{}, error) {
+	return ToChannel(context.Background(), src, buf)
+}
+
+// ToChannel send every element of src to the returned channel like ToChan,
+// eg: to stream big Ls results through worker stages. The channel is closed
+// early when ctx is done, so that the goroutine doesn't leak when the
+// receiver gives up.
+func ToChannel(ctx context.Context, src interface{}, buf int) (<-chan interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	ch := make(chan interface{}, buf)
+	go func() {
+		defer close(ch)
+		for i := 0; i < sv.Len(); i++ {
+			select {
+			case ch <- sv.Index(i).Interface():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// FromChan takes a channel of any element type and a pointer to slice,
+// it receives until the channel is closed and appends every value to dst.
+func FromChan(ch interface{}, dst interface{}) error {
+	return FromChannel(context.Background(), ch, dst, 0)
+}
+
+// FromChannel receive from ch and append to dst like FromChan, until ch is
+// closed, max values were received if max is positive, or ctx is done. In
+// the latter case the values received so far are in dst and ctx.Err() is returned.
+func FromChannel(ctx context.Context, ch interface{}, dst interface{}, max int) error {
+	cv := reflect.ValueOf(ch)
+	if cv.Kind() != reflect.Chan || cv.Type().ChanDir()&reflect.RecvDir == 0 {
+		return ErrNotChan
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	} else {
+		return notPointer("dst", dv)
+	}
+
+	if dv.Kind() == reflect.Array {
+		return fixedLength("dst", dv)
+	}
+
+	if !isSlice(dv) {
+		return notSlice("dst", dv)
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: cv},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+
+	et := dv.Type().Elem()
+	for n := 0; max <= 0 || n < max; n++ {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return ctx.Err()
+		}
+		if !ok {
+			return nil
+		}
+		// values from a chan interface{} carry their dynamic type
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		if !v.IsValid() || !v.Type().AssignableTo(et) {
+			return notSameType("ch", typeOf(v), et)
+		}
+		dv.Set(reflect.Append(dv, v))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package slice
+
+import "reflect"
+
+// Clone return a copy of the slice src with its own backing array, so that
+// the mutating helpers like Remove can work on it without touching src.
+// The elements themselves are copied as is, see DeepClone.
+func Clone(src interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	if sv.IsNil() {
+		return src, nil
+	}
+
+	tmp := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+	reflect.Copy(tmp, sv)
+	return tmp.Interface(), nil
+}
+
+// DeepClone return a copy of the slice src, recursively copying the nested
+// slices, arrays, maps, pointers and the exported fields of structs, so that
+// nothing is shared with src. Unexported fields are copied as is. Values
+// referenced several times, cycles included, are cloned once and stay shared
+// the same way in the copy.
+func DeepClone(src interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	return deepClone(sv, make(map[cloneKey]reflect.Value)).Interface(), nil
+}
+
+// cloneKey identify a pointer, map or slice already cloned
+type cloneKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func deepClone(v reflect.Value, seen map[cloneKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		k := cloneKey{v.Pointer(), v.Type(), v.Len()}
+		if c, ok := seen[k]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		seen[k] = c
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepClone(v.Index(i), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepClone(v.Index(i), seen))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		k := cloneKey{v.Pointer(), v.Type(), 0}
+		if c, ok := seen[k]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		seen[k] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepClone(iter.Key(), seen), deepClone(iter.Value(), seen))
+		}
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		k := cloneKey{v.Pointer(), v.Type(), 0}
+		if c, ok := seen[k]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[k] = c
+		c.Elem().Set(deepClone(v.Elem(), seen))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		// copies the unexported fields, which can't be set one by one
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepClone(v.Field(i), seen))
+			}
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepClone(v.Elem(), seen))
+		return c
+	}
+	return v
+}
@@ -0,0 +1,73 @@
+package slice
+
+import "reflect"
+
+// Compare compare two slices element by element, like strings.Compare does for
+// bytes: it returns -1 if a sorts before b, 1 if after and 0 if they are equal.
+// A slice sorts before a longer one it is a prefix of. The elements must be of
+// an ordered kind (integers, floats, strings) or slices of them.
+func Compare(a, b interface{}) (int, error) {
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+
+	for i, v := range []reflect.Value{av, bv} {
+		if !isSlice(v) && v.Kind() != reflect.Array {
+			return 0, notSlice([]string{"a", "b"}[i], v)
+		}
+	}
+
+	if av.Type().Elem() != bv.Type().Elem() {
+		return 0, notSameType("b", bv.Type(), av.Type())
+	}
+
+	return compareValues(av, bv)
+}
+
+// compareValues compare two values of the same ordered kind, or slices of them
+func compareValues(a, b reflect.Value) (int, error) {
+	switch a.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			c, err := compareValues(a.Index(i), b.Index(i))
+			if err != nil || c != 0 {
+				return c, err
+			}
+		}
+		return sign(a.Len() - b.Len()), nil
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Kind() != b.Elem().Kind() {
+			return 0, ErrNotSameType
+		}
+		return compareValues(a.Elem(), b.Elem())
+	}
+
+	if a.Kind() != b.Kind() {
+		return 0, ErrNotSameType
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp(a.Float() < b.Float(), a.Float() > b.Float()), nil
+	case reflect.String:
+		return cmp(a.String() < b.String(), a.String() > b.String()), nil
+	}
+	return 0, ErrNotSameType
+}
+
+func cmp(less, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
+
+func sign(n int) int {
+	return cmp(n < 0, n > 0)
+}
@@ -0,0 +1,289 @@
+package slice
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Fill set every element of src to value. src can be a slice or a pointer
+// to slice/array, value must be assignable to the element type.
+func Fill(src interface{}, value interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		// nil fills with the zero value
+		vv = reflect.Zero(sv.Type().Elem())
+	}
+	if !vv.Type().AssignableTo(sv.Type().Elem()) {
+		return notSameType("value", vv.Type(), sv.Type().Elem())
+	}
+
+	if sv.Kind() == reflect.Array && !sv.CanSet() {
+		return notPointer("src", reflect.ValueOf(src))
+	}
+
+	for i := 0; i < sv.Len(); i++ {
+		sv.Index(i).Set(vv)
+	}
+	return nil
+}
+
+// Repeat return a new slice of n copies of value, eg: Repeat("a", 3) returns []string{"a", "a", "a"}
+func Repeat(value interface{}, n int) (interface{}, error) {
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		return nil, ErrNotSameType
+	}
+	if n < 0 {
+		return nil, ErrOutOfRange
+	}
+
+	tmp := reflect.MakeSlice(reflect.SliceOf(vv.Type()), n, n)
+	for i := 0; i < n; i++ {
+		tmp.Index(i).Set(vv)
+	}
+	return tmp.Interface(), nil
+}
+
+// Grow takes a pointer to slice as source and makes sure it has capacity for
+// at least n more elements, so that the following appends don't reallocate.
+func Grow(src interface{}, n int) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	if n < 0 {
+		return ErrOutOfRange
+	}
+
+	if sv.Cap()-sv.Len() >= n {
+		return nil
+	}
+
+	tmp := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len()+n)
+	reflect.Copy(tmp, sv)
+	sv.Set(tmp)
+	return nil
+}
+
+// AppendN takes a pointer to slice as source and appends all values with a single reflect.Append
+func AppendN(src interface{}, values ...interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	et := sv.Type().Elem()
+	vals := make([]reflect.Value, len(values))
+	for i, v := range values {
+		vv := reflect.ValueOf(v)
+		if !vv.IsValid() {
+			vv = reflect.Zero(et)
+		}
+		if !vv.Type().AssignableTo(et) {
+			return notSameType(fmt.Sprintf("values[%d]", i), vv.Type(), et)
+		}
+		vals[i] = vv
+	}
+
+	sv.Set(reflect.Append(sv, vals...))
+	return nil
+}
+
+// Range return the integers from start up to, but not including, stop by step.
+// step can be negative for a descending range, a zero step is ErrOutOfRange.
+// Ranges ending near the limits of int don't overflow.
+func Range(start, stop, step int) ([]int, error) {
+	if step == 0 {
+		return nil, ErrOutOfRange
+	}
+	// the distance and the step as unsigned, which two's complement keeps right
+	var diff, ustep uint64
+	switch {
+	case step > 0 && start < stop:
+		diff, ustep = uint64(stop)-uint64(start), uint64(step)
+	case step < 0 && start > stop:
+		diff, ustep = uint64(start)-uint64(stop), -uint64(step)
+	default:
+		return nil, nil
+	}
+	n := diff / ustep
+	if diff%ustep != 0 {
+		n++
+	}
+	res := make([]int, n)
+	for k := range res {
+		res[k] = int(uint64(start) + uint64(k)*uint64(step))
+	}
+	return res, nil
+}
+
+// RangeFloat like Range for float64, every element is computed as start+i*step
+// so that rounding errors don't accumulate. NaN or infinite arguments, and
+// steps too small to change the values, are ErrOutOfRange.
+func RangeFloat(start, stop, step float64) ([]float64, error) {
+	for _, f := range []float64{start, stop, step} {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, ErrOutOfRange
+		}
+	}
+	if step == 0 {
+		return nil, ErrOutOfRange
+	}
+	var res []float64
+	for i := 0; ; i++ {
+		v := start + float64(i)*step
+		if (step > 0 && v >= stop) || (step < 0 && v <= stop) {
+			break
+		}
+		if i > 0 && v == res[i-1] {
+			return nil, ErrOutOfRange
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// RangeString return the characters from start to stop, both included,
+// eg: RangeString('a', 'e') returns []string{"a", "b", "c", "d", "e"}.
+// The range is descending if stop is before start.
+func RangeString(start, stop rune) []string {
+	step := rune(1)
+	if stop < start {
+		step = -1
+	}
+	var res []string
+	for r := start; ; r += step {
+		res = append(res, string(r))
+		if r == stop {
+			break
+		}
+	}
+	return res
+}
+
+// Of return a new slice of the type of values, eg: Of("a", "b") returns
+// []string{"a", "b"}, so that inputs of the functions of this package can be
+// built from interface{} values. All values must have the same type.
+func Of(values ...interface{}) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+
+	var et reflect.Type
+	for i, v := range values {
+		vv := reflect.ValueOf(v)
+		if !vv.IsValid() {
+			return nil, notSameType(fmt.Sprintf("values[%d]", i), nil, et)
+		}
+		if et == nil {
+			et = vv.Type()
+		} else if vv.Type() != et {
+			return nil, notSameType(fmt.Sprintf("values[%d]", i), vv.Type(), et)
+		}
+	}
+
+	tmp := reflect.MakeSlice(reflect.SliceOf(et), len(values), len(values))
+	for i, v := range values {
+		tmp.Index(i).Set(reflect.ValueOf(v))
+	}
+	return tmp.Interface(), nil
+}
+
+// Convert convert the elements of src into the slice dst points to, eg: from
+// []int to []int64 or from []fmt.Stringer to []string. Elements are converted
+// like Go conversions do, except integers, which don't convert to strings,
+// and fmt.Stringers, which convert to strings with their String method.
+// An inconvertible element is a TypeError and leaves dst untouched.
+func Convert(src interface{}, dst interface{}) error {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	} else {
+		return notPointer("dst", dv)
+	}
+
+	if dv.Kind() == reflect.Array {
+		return fixedLength("dst", dv)
+	}
+
+	if !isSlice(dv) {
+		return notSlice("dst", dv)
+	}
+
+	et := dv.Type().Elem()
+	tmp := reflect.MakeSlice(dv.Type(), sv.Len(), sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		v, ok := convert(sv.Index(i), et)
+		if !ok {
+			return notSameType(fmt.Sprintf("src[%d]", i), typeOf(v), et)
+		}
+		tmp.Index(i).Set(v)
+	}
+
+	dv.Set(tmp)
+	return nil
+}
+
+// convert v to type t, returning the unwrapped v if it can't be converted
+func convert(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() && t.Kind() != reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+			return reflect.Zero(t), true
+		}
+		return reflect.Value{}, false
+	}
+
+	if t.Kind() == reflect.String && v.Kind() != reflect.String {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return reflect.ValueOf(s.String()).Convert(t), true
+		}
+		if isNumeric(v.Kind()) {
+			return v, false
+		}
+	}
+
+	if !v.Type().ConvertibleTo(t) {
+		return v, false
+	}
+	return v.Convert(t), true
+}
@@ -0,0 +1,105 @@
+package slice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		start, stop, step int
+		correct           []int
+		err               error
+	}{
+		{0, 5, 2, []int{0, 2, 4}, nil},
+		{5, 0, -2, []int{5, 3, 1}, nil},
+		{0, 0, 1, nil, nil},
+		{0, 5, 0, nil, ErrOutOfRange},
+		{maxInt - 3, maxInt, 2, []int{maxInt - 3, maxInt - 1}, nil},
+		{minInt + 3, minInt, -2, []int{minInt + 3, minInt + 1}, nil},
+	}
+	for _, tc := range tests {
+		res, err := Range(tc.start, tc.stop, tc.step)
+		if !reflect.DeepEqual(res, tc.correct) || err != tc.err {
+			t.Errorf("[slice]: Range test failed, expecting %v, got %v, err %v", tc.correct, res, err)
+		}
+	}
+}
+
+func TestRangeFloat(t *testing.T) {
+	tests := []struct {
+		start, stop, step float64
+		correct           []float64
+		err               error
+	}{
+		{0, 1, 0.25, []float64{0, 0.25, 0.5, 0.75}, nil},
+		{1, 0, -0.5, []float64{1, 0.5}, nil},
+		{0, 1, math.NaN(), nil, ErrOutOfRange},
+		{0, math.Inf(1), 1, nil, ErrOutOfRange},
+		{1e16, 1e16 + 10, 1, nil, ErrOutOfRange},
+	}
+	for _, tc := range tests {
+		res, err := RangeFloat(tc.start, tc.stop, tc.step)
+		if !reflect.DeepEqual(res, tc.correct) || err != tc.err {
+			t.Errorf("[slice]: RangeFloat test failed, expecting %v, got %v, err %v", tc.correct, res, err)
+		}
+	}
+}
+
+type stringerInt int
+
+func (s stringerInt) String() string { return fmt.Sprintf("#%d", int(s)) }
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		values  []interface{}
+		correct interface{}
+		err     error
+	}{
+		{[]interface{}{"a", "b"}, []string{"a", "b"}, nil},
+		{[]interface{}{1}, []int{1}, nil},
+		{nil, nil, ErrEmpty},
+		{[]interface{}{1, "a"}, nil, ErrNotSameType},
+		{[]interface{}{1, nil}, nil, ErrNotSameType},
+	}
+	for _, tc := range tests {
+		res, err := Of(tc.values...)
+		if !reflect.DeepEqual(res, tc.correct) || !errors.Is(err, tc.err) {
+			t.Errorf("[slice]: Of test failed, expecting %v, got %v, err %v", tc.correct, res, err)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		dst     interface{}
+		correct interface{}
+		err     error
+	}{
+		{[]int{1, 2}, &[]int64{}, &[]int64{1, 2}, nil},
+		{[2]float64{1.5, 2}, &[]float32{}, &[]float32{1.5, 2}, nil},
+		{[]stringerInt{1, 2}, &[]string{}, &[]string{"#1", "#2"}, nil},
+		{[]interface{}{"a", nil}, &[]*string{}, nil, ErrNotSameType},
+		{[]interface{}{nil}, &[]error{}, &[]error{nil}, nil},
+		{[]int{65}, &[]string{"keep"}, &[]string{"keep"}, ErrNotSameType},
+		{[]string{"a"}, &[]int{7}, &[]int{7}, ErrNotSameType},
+		{[]int{1}, []int64{}, nil, ErrNotPointer},
+		{[]int{1}, &[1]int64{}, nil, ErrFixedLength},
+		{1, &[]int{}, nil, ErrNotSlice},
+	}
+	for i, tc := range tests {
+		err := Convert(tc.src, tc.dst)
+		if !errors.Is(err, tc.err) || (tc.correct != nil && !reflect.DeepEqual(tc.dst, tc.correct)) {
+			t.Errorf("[slice]: Convert test %d failed, expecting %v, got %v, err %v", i, tc.correct, tc.dst, err)
+		}
+	}
+}
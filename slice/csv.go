@@ -0,0 +1,288 @@
+package slice
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+type csvOptions struct {
+	comma    rune
+	noHeader bool
+}
+
+// CSVOption configures ToCSV and FromCSV
+type CSVOption func(*csvOptions)
+
+// WithComma separate the fields with r instead of ','
+func WithComma(r rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = r
+	}
+}
+
+// WithoutHeader ToCSV does not write the header row
+func WithoutHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.noHeader = true
+	}
+}
+
+// csvField an exported struct field and its column name
+type csvField struct {
+	index int
+	name  string
+}
+
+// csvFields the columns of struct type t: the exported fields named by
+// their "csv" tag or their name, the ones tagged "-" are skipped
+func csvFields(t reflect.Type) []csvField {
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if len(tag) > 0 {
+				name = tag
+			}
+		}
+		fields = append(fields, csvField{i, name})
+	}
+	return fields
+}
+
+// structType the struct type of the elements of slice type t, which may be pointers
+func structType(t reflect.Type) (reflect.Type, bool) {
+	et := t.Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return et, et.Kind() == reflect.Struct
+}
+
+// ToCSV write the slice of structs (or pointers to structs) src to w, one
+// row per element after a header row of the column names. Booleans, numbers,
+// strings and encoding.TextMarshaler fields (eg: time.Time) are supported, so
+// are pointers to them for optional fields, nil being an empty cell.
+func ToCSV(src interface{}, w io.Writer, opts ...CSVOption) error {
+	o := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	st, ok := structType(sv.Type())
+	if !ok {
+		return notSameType("src", sv.Type(), nil)
+	}
+	fields := csvFields(st)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+
+	row := make([]string, len(fields))
+	if !o.noHeader {
+		for i, f := range fields {
+			row[i] = f.name
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < sv.Len(); i++ {
+		v := sv.Index(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		for j, f := range fields {
+			s, err := formatCSV(v.Field(f.index))
+			if err != nil {
+				return fmt.Errorf("row %d, column %s: %w", i, f.name, err)
+			}
+			row[j] = s
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// FromCSV read the rows of r into dst, a pointer to slice of structs (or
+// pointers to structs), appending one element per row. The first row is the
+// header naming the columns like ToCSV does, unknown columns are ignored.
+func FromCSV(r io.Reader, dst interface{}, opts ...CSVOption) error {
+	o := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	} else {
+		return notPointer("dst", dv)
+	}
+
+	if dv.Kind() == reflect.Array {
+		return fixedLength("dst", dv)
+	}
+
+	if !isSlice(dv) {
+		return notSlice("dst", dv)
+	}
+
+	st, ok := structType(dv.Type())
+	if !ok {
+		return notSameType("dst", dv.Type(), nil)
+	}
+	isPtr := dv.Type().Elem().Kind() == reflect.Ptr
+
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	byName := make(map[string]int)
+	for _, f := range csvFields(st) {
+		byName[f.name] = f.index
+	}
+	columns := make([]int, len(header))
+	for i, h := range header {
+		idx, ok := byName[h]
+		if !ok {
+			idx = -1
+		}
+		columns[i] = idx
+	}
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v := reflect.New(st).Elem()
+		for i, s := range row {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+			if err := parseCSV(v.Field(columns[i]), s); err != nil {
+				return fmt.Errorf("line %d, column %s: %w", line, header[i], err)
+			}
+		}
+		if isPtr {
+			v = v.Addr()
+		}
+		dv.Set(reflect.Append(dv, v))
+	}
+}
+
+func formatCSV(v reflect.Value) (string, error) {
+	// optional fields, nil is an empty cell
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatCSV(v.Elem())
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", notSameType("field", v.Type(), nil)
+}
+
+func parseCSV(v reflect.Value, s string) error {
+	// optional fields, an empty cell is nil
+	if v.Kind() == reflect.Ptr {
+		if len(s) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		p := reflect.New(v.Type().Elem())
+		if err := parseCSV(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return notSameType("field", v.Type(), nil)
+	}
+	return nil
+}
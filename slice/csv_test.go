@@ -0,0 +1,87 @@
+package slice
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvRow struct {
+	Name string
+	Age  *int       `csv:"age"`
+	Seen *time.Time `csv:"seen"`
+}
+
+func TestCSVOptionalFields(t *testing.T) {
+	age := 42
+	seen := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	src := []csvRow{{"a", &age, &seen}, {"b", nil, nil}}
+
+	var buf bytes.Buffer
+	if err := ToCSV(src, &buf); err != nil {
+		t.Fatalf("[slice]: ToCSV test failed with %s", err)
+	}
+	correct := "Name,age,seen\na,42,2021-06-01T12:00:00Z\nb,,\n"
+	if buf.String() != correct {
+		t.Errorf("[slice]: ToCSV test failed, expecting %q, got %q", correct, buf.String())
+	}
+
+	var dst []csvRow
+	if err := FromCSV(&buf, &dst); !reflect.DeepEqual(dst, src) || err != nil {
+		t.Errorf("[slice]: FromCSV test failed, expecting %v, got %v, err %v", src, dst, err)
+	}
+}
+
+type csvItem struct {
+	Name  string  `csv:"name"`
+	Count uint16  `csv:"count"`
+	Ratio float32 `csv:"ratio"`
+	OK    bool
+	Skip  string `csv:"-"`
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     []csvItem
+		opts    []CSVOption
+		correct string
+	}{
+		{"default", []csvItem{{"a", 1, 0.5, true, "x"}, {"b,c", 2, 1, false, ""}},
+			nil, "name,count,ratio,OK\na,1,0.5,true\n\"b,c\",2,1,false\n"},
+		{"comma", []csvItem{{"a", 1, 0.5, true, ""}}, []CSVOption{WithComma(';')}, "name;count;ratio;OK\na;1;0.5;true\n"},
+		{"no header", []csvItem{{"a", 1, 0.5, true, ""}}, []CSVOption{WithoutHeader()}, "a,1,0.5,true\n"},
+		{"empty", nil, nil, "name,count,ratio,OK\n"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := ToCSV(tc.src, &buf, tc.opts...); buf.String() != tc.correct || err != nil {
+			t.Errorf("[slice]: ToCSV %s test failed, expecting %q, got %q, err %v", tc.name, tc.correct, buf.String(), err)
+		}
+	}
+
+	// the columns are matched by name, in any order, unknown ones are ignored
+	var dst []*csvItem
+	err := FromCSV(strings.NewReader("extra;OK;name\n1;true;a\n2;false;b\n"), &dst, WithComma(';'))
+	correct := []*csvItem{{Name: "a", OK: true}, {Name: "b"}}
+	if !reflect.DeepEqual(dst, correct) || err != nil {
+		t.Errorf("[slice]: FromCSV test failed, expecting %v, got %v, err %v", correct, dst, err)
+	}
+
+	var items []csvItem
+	if err := FromCSV(strings.NewReader(""), &items); len(items) != 0 || err != nil {
+		t.Errorf("[slice]: FromCSV test failed, expecting nothing, got %v, err %v", items, err)
+	}
+	if err := FromCSV(strings.NewReader("count\n70000\n"), &items); err == nil || !strings.Contains(err.Error(), "line 2, column count") {
+		t.Errorf("[slice]: FromCSV test failed, expecting an out of range error on line 2, got %v", err)
+	}
+	if err := FromCSV(strings.NewReader("name\n"), items); !errors.Is(err, ErrNotPointer) {
+		t.Errorf("[slice]: FromCSV test failed, expecting %v, got %v", ErrNotPointer, err)
+	}
+	if err := ToCSV([]int{1}, &bytes.Buffer{}); !errors.Is(err, ErrNotSameType) {
+		t.Errorf("[slice]: ToCSV test failed, expecting %v, got %v", ErrNotSameType, err)
+	}
+}
@@ -0,0 +1,123 @@
+package slice
+
+import "reflect"
+
+// EditOp the operation of an Edit
+type EditOp int
+
+const (
+	// Keep the element is in both slices
+	Keep EditOp = iota
+	// Insert the element is only in the new slice
+	Insert
+	// Delete the element is only in the old slice
+	Delete
+)
+
+func (op EditOp) String() string {
+	switch op {
+	case Insert:
+		return "+"
+	case Delete:
+		return "-"
+	}
+	return " "
+}
+
+// Edit one step of the edit script returned by Diff. OldIndex is -1 for
+// insertions and NewIndex is -1 for deletions.
+type Edit struct {
+	Op       EditOp
+	OldIndex int
+	NewIndex int
+	Value    interface{}
+}
+
+// Diff compute the edit script turning old into new, based on their longest
+// common subsequence, eg: to show what changed between two file lists.
+// Elements are equal if they are deeply equal. Applying the edits in order,
+// keeping and inserting elements while skipping the deleted ones, builds new.
+// It takes O(len(old)*len(new)) time and memory after trimming the common
+// prefix and suffix.
+func Diff(old, new interface{}) ([]Edit, error) {
+	ov := reflect.ValueOf(old)
+	nv := reflect.ValueOf(new)
+
+	for i, v := range []reflect.Value{ov, nv} {
+		if !isSlice(v) && v.Kind() != reflect.Array {
+			return nil, notSlice([]string{"old", "new"}[i], v)
+		}
+	}
+
+	if ov.Type().Elem() != nv.Type().Elem() {
+		return nil, notSameType("new", nv.Type(), ov.Type())
+	}
+
+	oldKeys := make([]interface{}, ov.Len())
+	for i := range oldKeys {
+		oldKeys[i] = genKey(ov.Index(i))
+	}
+	newKeys := make([]interface{}, nv.Len())
+	for i := range newKeys {
+		newKeys[i] = genKey(nv.Index(i))
+	}
+
+	// the common prefix and suffix are kept as is
+	pre := 0
+	for pre < len(oldKeys) && pre < len(newKeys) && oldKeys[pre] == newKeys[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(oldKeys)-pre && suf < len(newKeys)-pre && oldKeys[len(oldKeys)-1-suf] == newKeys[len(newKeys)-1-suf] {
+		suf++
+	}
+
+	edits := make([]Edit, 0, len(oldKeys)+len(newKeys)-pre-suf)
+	keep := func(i, j int) {
+		edits = append(edits, Edit{Op: Keep, OldIndex: i, NewIndex: j, Value: ov.Index(i).Interface()})
+	}
+
+	for i := 0; i < pre; i++ {
+		keep(i, i)
+	}
+
+	a, b := oldKeys[pre:len(oldKeys)-suf], newKeys[pre:len(newKeys)-suf]
+	// lcs[i][j] the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			keep(pre+i, pre+j)
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, Edit{Op: Delete, OldIndex: pre + i, NewIndex: -1, Value: ov.Index(pre + i).Interface()})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, OldIndex: -1, NewIndex: pre + j, Value: nv.Index(pre + j).Interface()})
+			j++
+		}
+	}
+
+	for k := suf; k > 0; k-- {
+		keep(ov.Len()-k, nv.Len()-k)
+	}
+
+	return edits, nil
+}
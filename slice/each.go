@@ -0,0 +1,93 @@
+package slice
+
+import "reflect"
+
+// Each call fn with every element of src, a slice, an array or a pointer to
+// them, in order. Returning false from fn stops the iteration. Unlike the
+// functions taking func(interface{}), the elements are not boxed into
+// interfaces, which allocates for most non-pointer types: for
+// multi-million element slices, read them with v.Int(), v.String()...
+func Each(src interface{}, fn func(v reflect.Value) bool) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	for i := 0; i < sv.Len(); i++ {
+		if !fn(sv.Index(i)) {
+			break
+		}
+	}
+	return nil
+}
+
+// EachInto takes a pointer to a variable of the element type of src as dst,
+// and sets it to every element of src before calling fn with its index, eg:
+//
+//	var s string
+//	EachInto(names, &s, func(i int) bool { fmt.Println(i, s); return true })
+//
+// The variable is reused, so nothing is allocated per element.
+func EachInto(src interface{}, dst interface{}, fn func(i int) bool) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr {
+		return notPointer("dst", dv)
+	}
+	dv = dv.Elem()
+
+	if !sv.Type().Elem().AssignableTo(dv.Type()) {
+		return notSameType("dst", dv.Type(), sv.Type().Elem())
+	}
+
+	for i := 0; i < sv.Len(); i++ {
+		dv.Set(sv.Index(i))
+		if !fn(i) {
+			break
+		}
+	}
+	return nil
+}
+
+// EachBatch call fn with consecutive batches of size elements of src, the
+// last one may be shorter. The batches share the backing array of src, so
+// they are not copied, and type asserting batch.Interface() to the slice type
+// gives back typed access to its elements. Returning false from fn stops the iteration.
+func EachBatch(src interface{}, size int, fn func(batch reflect.Value) bool) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	if size < 1 {
+		return ErrOutOfRange
+	}
+
+	for i := 0; i < sv.Len(); i += size {
+		end := i + size
+		if end > sv.Len() {
+			end = sv.Len()
+		}
+		// full slice expression, so that appending to a batch does not overwrite src
+		if !fn(sv.Slice3(i, end, end)) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeError the argument Arg of a function has type Got, where type Want
+// (or a kind of type, like a slice) was expected. It wraps one of the
+// sentinel errors, so that errors.Is(err, ErrNotSlice) still works.
+type TypeError struct {
+	Arg  string
+	Got  reflect.Type
+	Want reflect.Type
+	Err  error
+}
+
+func (e *TypeError) Error() string {
+	got := "nil"
+	if e.Got != nil {
+		got = e.Got.String()
+	}
+	if e.Want != nil {
+		return fmt.Sprintf("%s: %s, got %s, want %s", e.Arg, e.Err, got, e.Want)
+	}
+	return fmt.Sprintf("%s: %s, got %s", e.Arg, e.Err, got)
+}
+
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+func notSlice(arg string, v reflect.Value) error {
+	return &TypeError{Arg: arg, Got: typeOf(v), Err: ErrNotSlice}
+}
+
+func notPointer(arg string, v reflect.Value) error {
+	return &TypeError{Arg: arg, Got: typeOf(v), Err: ErrNotPointer}
+}
+
+func fixedLength(arg string, v reflect.Value) error {
+	return &TypeError{Arg: arg, Got: typeOf(v), Err: ErrFixedLength}
+}
+
+func notSameType(arg string, got, want reflect.Type) error {
+	return &TypeError{Arg: arg, Got: got, Want: want, Err: ErrNotSameType}
+}
@@ -0,0 +1,121 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FillStructSlice append one element to dst, a pointer to slice of structs
+// (or pointers to structs), per row of rows, eg: rows parsed from INI, CSV
+// or JSON. The keys of a row are matched against the tag of the exported
+// fields, the part before any comma, or their names, case-insensitively if
+// there is no exact match. Fields tagged "-" and unknown keys are ignored.
+// Values are converted like Convert does, and strings are parsed for
+// booleans, numbers and encoding.TextUnmarshaler fields like FromCSV does.
+func FillStructSlice(dst interface{}, rows []map[string]interface{}, tag string) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	} else {
+		return notPointer("dst", dv)
+	}
+
+	if dv.Kind() == reflect.Array {
+		return fixedLength("dst", dv)
+	}
+
+	if !isSlice(dv) {
+		return notSlice("dst", dv)
+	}
+
+	st, ok := structType(dv.Type())
+	if !ok {
+		return notSameType("dst", dv.Type(), nil)
+	}
+	isPtr := dv.Type().Elem().Kind() == reflect.Ptr
+	fields := tagFields(st, tag)
+
+	tmp := reflect.MakeSlice(dv.Type(), 0, len(rows))
+	for i, row := range rows {
+		v := reflect.New(st).Elem()
+		for k, val := range row {
+			idx, ok := fields[k]
+			if !ok {
+				idx, ok = fields[strings.ToLower(k)]
+			}
+			if !ok {
+				continue
+			}
+			if err := setField(v.Field(idx), val); err != nil {
+				return fmt.Errorf("row %d, key %s: %w", i, k, err)
+			}
+		}
+		if isPtr {
+			v = v.Addr()
+		}
+		tmp = reflect.Append(tmp, v)
+	}
+
+	dv.Set(reflect.AppendSlice(dv, tmp))
+	return nil
+}
+
+// tagFields the indexes of the exported fields of struct type t by their tag
+// name or field name, and by the lower case of it when not ambiguous
+func tagFields(t reflect.Type, tag string) map[string]int {
+	fields := make(map[string]int)
+	lower := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		name := f.Name
+		if v, ok := f.Tag.Lookup(tag); ok {
+			v = strings.Split(v, ",")[0]
+			if v == "-" {
+				continue
+			}
+			if len(v) > 0 {
+				name = v
+			}
+		}
+		fields[name] = i
+		if _, ok := lower[strings.ToLower(name)]; ok {
+			lower[strings.ToLower(name)] = -1
+		} else {
+			lower[strings.ToLower(name)] = i
+		}
+	}
+	for k, i := range lower {
+		if _, ok := fields[k]; !ok && i >= 0 {
+			fields[k] = i
+		}
+	}
+	return fields
+}
+
+// setField set the field v to val
+func setField(v reflect.Value, val interface{}) error {
+	vv := reflect.ValueOf(val)
+	if s, ok := val.(string); ok && v.Kind() != reflect.String {
+		return parseCSV(v, s)
+	}
+
+	cv, ok := convert(vv, v.Type())
+	if !ok {
+		return notSameType("value", typeOf(cv), v.Type())
+	}
+	// floats only convert to integers without a fractional part, eg: JSON numbers
+	if k := vv.Kind(); (k == reflect.Float32 || k == reflect.Float64) && !isFloatKind(v.Kind()) &&
+		cv.Convert(vv.Type()).Float() != vv.Float() {
+		return fmt.Errorf("%v has a fractional part: %w", val, ErrOutOfRange)
+	}
+	v.Set(cv)
+	return nil
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,61 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fillRow struct {
+	Name    string `ini:"name,omitempty"`
+	Size    int64
+	Enabled bool
+	Seen    time.Time `ini:"seen"`
+	Skip    string    `ini:"-"`
+	Ratio   float64
+	hidden  int
+}
+
+func TestFillStructSlice(t *testing.T) {
+	seen := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		rows    []map[string]interface{}
+		correct []fillRow
+		err     bool
+	}{
+		{"tags and names", []map[string]interface{}{{"name": "a", "Size": 3, "Enabled": true}},
+			[]fillRow{{Name: "a", Size: 3, Enabled: true}}, false},
+		{"case-insensitive", []map[string]interface{}{{"NAME": "b", "size": int8(4), "ratio": float32(0.5)}},
+			[]fillRow{{Name: "b", Size: 4, Ratio: 0.5}}, false},
+		{"strings parsed", []map[string]interface{}{{"Size": "5", "Enabled": "true", "seen": "2021-06-01T12:00:00Z"}},
+			[]fillRow{{Size: 5, Enabled: true, Seen: seen}}, false},
+		{"ignored keys", []map[string]interface{}{{"Skip": "x", "hidden": 1, "unknown": 2}},
+			[]fillRow{{}}, false},
+		{"JSON number", []map[string]interface{}{{"Size": 6.0}}, []fillRow{{Size: 6}}, false},
+		{"fractional", []map[string]interface{}{{"Size": 6.5}}, nil, true},
+		{"bad string", []map[string]interface{}{{"Size": "six"}}, nil, true},
+		{"inconvertible", []map[string]interface{}{{"Enabled": 1}}, nil, true},
+	}
+	for _, tc := range tests {
+		var dst []fillRow
+		err := FillStructSlice(&dst, tc.rows, "ini")
+		if !reflect.DeepEqual(dst, tc.correct) || (err != nil) != tc.err {
+			t.Errorf("[slice]: FillStructSlice %s test failed, expecting %v, got %v, err %v", tc.name, tc.correct, dst, err)
+		}
+	}
+
+	ptrs := []*fillRow{{Name: "a"}}
+	err := FillStructSlice(&ptrs, []map[string]interface{}{{"Name": "b"}}, "")
+	if len(ptrs) != 2 || ptrs[1].Name != "b" || err != nil {
+		t.Errorf("[slice]: FillStructSlice test failed, expecting b appended, got %v, err %v", ptrs, err)
+	}
+
+	if err := FillStructSlice([]fillRow{}, nil, "ini"); !errors.Is(err, ErrNotPointer) {
+		t.Errorf("[slice]: FillStructSlice test failed, expecting %v, got %v", ErrNotPointer, err)
+	}
+	if err := FillStructSlice(&[]int{}, nil, "ini"); !errors.Is(err, ErrNotSameType) {
+		t.Errorf("[slice]: FillStructSlice test failed, expecting %v, got %v", ErrNotSameType, err)
+	}
+}
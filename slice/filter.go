@@ -0,0 +1,88 @@
+package slice
+
+import "reflect"
+
+// Filter return a new slice of the elements in src satisfying pred
+func Filter(src interface{}, pred func(interface{}) bool) (interface{}, error) {
+	return filter(src, pred, true)
+}
+
+// Reject return a new slice of the elements in src not satisfying pred,
+// the inverse of Filter
+func Reject(src interface{}, pred func(interface{}) bool) (interface{}, error) {
+	return filter(src, pred, false)
+}
+
+// RemoveIfAll takes a pointer to slice as source and removes every element
+// satisfying pred in place. It returns how many elements were removed.
+func RemoveIfAll(src interface{}, pred func(interface{}) bool) (int, error) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return 0, notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return 0, fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return 0, notSlice("src", sv)
+	}
+
+	idx := []int{}
+	for i := 0; i < sv.Len(); i++ {
+		if pred(sv.Index(i).Interface()) {
+			idx = append(idx, i)
+		}
+	}
+
+	if len(idx) > 0 {
+		sv.Set(removeFromSlice(idx, sv))
+	}
+
+	return len(idx), nil
+}
+
+func filter(src interface{}, pred func(interface{}) bool, keep bool) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	tmp := reflect.MakeSlice(sv.Type(), 0, 0)
+	for i := 0; i < sv.Len(); i++ {
+		if pred(sv.Index(i).Interface()) == keep {
+			tmp = reflect.Append(tmp, sv.Index(i))
+		}
+	}
+
+	return tmp.Interface(), nil
+}
+
+// Any whether at least one element of src satisfies pred
+func Any(src interface{}, pred func(interface{}) bool) (bool, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return false, notSlice("src", sv)
+	}
+	for i := 0; i < sv.Len(); i++ {
+		if pred(sv.Index(i).Interface()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// All whether every element of src satisfies pred, true for an empty slice
+func All(src interface{}, pred func(interface{}) bool) (bool, error) {
+	ok, err := Any(src, func(v interface{}) bool { return !pred(v) })
+	return !ok && err == nil, err
+}
+
+// None whether no element of src satisfies pred, true for an empty slice
+func None(src interface{}, pred func(interface{}) bool) (bool, error) {
+	ok, err := Any(src, pred)
+	return !ok && err == nil, err
+}
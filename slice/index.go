@@ -0,0 +1,36 @@
+package slice
+
+import "reflect"
+
+// Index a hash index over a slice for repeated membership tests in O(1),
+// where Contains is O(n) per call. It is a snapshot: later changes to the
+// source slice are not reflected.
+type Index struct {
+	m map[interface{}][]int
+}
+
+// NewIndex build an Index over the elements of src
+func NewIndex(src interface{}) (*Index, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	idx := &Index{m: make(map[interface{}][]int, sv.Len())}
+	for i := 0; i < sv.Len(); i++ {
+		k := genKey(sv.Index(i))
+		idx.m[k] = append(idx.m[k], i)
+	}
+	return idx, nil
+}
+
+// Has whether the indexed slice contains element
+func (idx *Index) Has(element interface{}) bool {
+	_, ok := idx.m[genKey(reflect.ValueOf(element))]
+	return ok
+}
+
+// Positions the indexes element is found at in the indexed slice, in ascending order
+func (idx *Index) Positions(element interface{}) []int {
+	return idx.m[genKey(reflect.ValueOf(element))]
+}
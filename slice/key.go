@@ -0,0 +1,132 @@
+package slice
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// deepKey the map key of values that are not comparable, or compare by identity
+// where their content matters. It is a distinct type, so it never equals a string element.
+type deepKey string
+
+// genKey generate map key. Interfaces are unwrapped, comparable basic kinds are
+// used as is, everything else (structs, arrays, slices, maps, pointers) is encoded deeply
+// by content, so that two elements get the same key if they are deeply equal.
+// Cycles through pointers, maps or slices are detected and encoded by depth.
+func genKey(v reflect.Value) interface{} {
+	// elements of []interface{} are keyed by their dynamic value, so 1 in an
+	// []interface{} gets the same key as 1 in an []int
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+	}
+
+	var b strings.Builder
+	encodeKey(&b, v, make(map[uintptr]int), 0)
+	return deepKey(b.String())
+}
+
+// encodeKey write a deterministic encoding of v to b. seen maps the address
+// of the pointers, maps and slices being encoded to their depth.
+func encodeKey(b *strings.Builder, v reflect.Value, seen map[uintptr]int, depth int) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+
+	b.WriteString(v.Type().String())
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		b.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		p := v.Pointer()
+		// an empty slice may share its address with anything, it can't form a cycle
+		if v.Kind() != reflect.Slice || v.Len() > 0 {
+			if d, ok := seen[p]; ok {
+				b.WriteString("cycle" + strconv.Itoa(depth-d))
+				return
+			}
+			seen[p] = depth
+			defer delete(seen, p)
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			b.WriteString("&")
+			encodeKey(b, v.Elem(), seen, depth+1)
+		case reflect.Slice:
+			encodeList(b, v, seen, depth)
+		case reflect.Map:
+			// map iteration order is random, sort the encoded entries
+			entries := make([]string, 0, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				var e strings.Builder
+				encodeKey(&e, iter.Key(), seen, depth+1)
+				e.WriteString(":")
+				encodeKey(&e, iter.Value(), seen, depth+1)
+				entries = append(entries, e.String())
+			}
+			sort.Strings(entries)
+			b.WriteString("{" + strings.Join(entries, ",") + "}")
+		}
+	case reflect.Array:
+		encodeList(b, v, seen, depth)
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			b.WriteString(v.Type().Field(i).Name + ":")
+			encodeKey(b, v.Field(i), seen, depth+1)
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString("(")
+		encodeKey(b, v.Elem(), seen, depth+1)
+		b.WriteString(")")
+	default:
+		// funcs, channels and unsafe pointers are only equal to themselves
+		b.WriteString("@" + strconv.FormatUint(uint64(v.Pointer()), 16))
+	}
+}
+
+func encodeList(b *strings.Builder, v reflect.Value, seen map[uintptr]int, depth int) {
+	b.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		encodeKey(b, v.Index(i), seen, depth+1)
+		b.WriteString(",")
+	}
+	b.WriteString("]")
+}
@@ -0,0 +1,29 @@
+package slice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntersectInterface(t *testing.T) {
+	src := []interface{}{1, 2, 3}
+	correct := []interface{}{2, 3}
+	if err := Intersect(&src, []int{2, 3}); !reflect.DeepEqual(src, correct) || err != nil {
+		t.Errorf("[slice]: Intersect test failed, expecting %v, got %v, err %v", correct, src, err)
+	}
+}
+
+func TestConcatInterface(t *testing.T) {
+	src := []interface{}{"a", "b"}
+	correct := []interface{}{"a", "b", "c"}
+	if err := Concat(&src, []string{"a", "c"}); !reflect.DeepEqual(src, correct) || err != nil {
+		t.Errorf("[slice]: Concat test failed, expecting %v, got %v, err %v", correct, src, err)
+	}
+}
+
+func TestIndexInterface(t *testing.T) {
+	idx, err := NewIndex([]interface{}{1, "a"})
+	if err != nil || !idx.Has(1) || !idx.Has("a") {
+		t.Errorf("[slice]: Index test failed, expecting 1 and a indexed, err %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DuplicatePolicy decides what ToMap does when two elements produce the same key
+type DuplicatePolicy int
+
+const (
+	// DuplicateError fail with ErrDuplicate
+	DuplicateError DuplicatePolicy = iota
+	// KeepFirst keep the value of the first element with the key
+	KeepFirst
+	// KeepLast keep the value of the last element with the key
+	KeepLast
+)
+
+// ToMap build a map from src keyed by keyFn, the values are the elements
+// themselves or what the optional valFn returns for them.
+func ToMap(src interface{}, keyFn func(interface{}) interface{}, policy DuplicatePolicy, valFn ...func(interface{}) interface{}) (map[interface{}]interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	m := make(map[interface{}]interface{}, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		e := sv.Index(i).Interface()
+		k := keyFn(e)
+		if _, ok := m[k]; ok {
+			switch policy {
+			case KeepFirst:
+				continue
+			case DuplicateError:
+				return m, fmt.Errorf("%w: %v at index %d", ErrDuplicate, k, i)
+			}
+		}
+		if len(valFn) > 0 {
+			m[k] = valFn[0](e)
+		} else {
+			m[k] = e
+		}
+	}
+
+	return m, nil
+}
+
+// Pair two elements sharing a key, see AlignByKey
+type Pair struct {
+	Key   interface{}
+	Left  interface{}
+	Right interface{}
+}
+
+// AlignByKey outer-join the slices a and b on the key keyFn computes for their elements.
+// It returns the matched pairs in the order of a, and new slices of the same
+// types as a and b holding their unmatched elements. Elements with duplicated
+// keys are paired in order of appearance.
+func AlignByKey(a, b interface{}, keyFn func(interface{}) interface{}) (pairs []Pair, left, right interface{}, err error) {
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+
+	for i, v := range []reflect.Value{av, bv} {
+		if !isSlice(v) {
+			return nil, nil, nil, notSlice([]string{"a", "b"}[i], v)
+		}
+	}
+
+	m := make(map[interface{}][]int)
+	for i := 0; i < bv.Len(); i++ {
+		k := keyFn(bv.Index(i).Interface())
+		m[k] = append(m[k], i)
+	}
+
+	lv := reflect.MakeSlice(av.Type(), 0, 0)
+	matched := make([]bool, bv.Len())
+	for i := 0; i < av.Len(); i++ {
+		e := av.Index(i).Interface()
+		k := keyFn(e)
+		if idx := m[k]; len(idx) > 0 {
+			pairs = append(pairs, Pair{Key: k, Left: e, Right: bv.Index(idx[0]).Interface()})
+			matched[idx[0]] = true
+			m[k] = idx[1:]
+			continue
+		}
+		lv = reflect.Append(lv, av.Index(i))
+	}
+
+	rv := reflect.MakeSlice(bv.Type(), 0, 0)
+	for i, ok := range matched {
+		if !ok {
+			rv = reflect.Append(rv, bv.Index(i))
+		}
+	}
+
+	return pairs, lv.Interface(), rv.Interface(), nil
+}
+
+// Keys return a new slice of the keys of the map m, eg: []string for a
+// map[string]int. They are in random order, like map iteration, unless sorted
+// is true, in which case they must be of an ordered kind.
+func Keys(m interface{}, sorted ...bool) (interface{}, error) {
+	mv, keys, err := mapKeys(m, len(sorted) > 0 && sorted[0])
+	if err != nil {
+		return nil, err
+	}
+	tmp := reflect.MakeSlice(reflect.SliceOf(mv.Type().Key()), len(keys), len(keys))
+	for i, k := range keys {
+		tmp.Index(i).Set(k)
+	}
+	return tmp.Interface(), nil
+}
+
+// Values return a new slice of the values of the map m. They are in random
+// order unless sorted is true, in which case they are in the order of the
+// sorted keys, so that they line up with the result of Keys.
+func Values(m interface{}, sorted ...bool) (interface{}, error) {
+	mv, keys, err := mapKeys(m, len(sorted) > 0 && sorted[0])
+	if err != nil {
+		return nil, err
+	}
+	tmp := reflect.MakeSlice(reflect.SliceOf(mv.Type().Elem()), len(keys), len(keys))
+	for i, k := range keys {
+		tmp.Index(i).Set(mv.MapIndex(k))
+	}
+	return tmp.Interface(), nil
+}
+
+// mapKeys the keys of the map m, sorted in ascending order if asked
+func mapKeys(m interface{}, sorted bool) (reflect.Value, []reflect.Value, error) {
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		return mv, nil, &TypeError{Arg: "m", Got: typeOf(mv), Err: ErrNotMap}
+	}
+
+	keys := mv.MapKeys()
+	if !sorted {
+		return mv, keys, nil
+	}
+
+	var err error
+	sort.Slice(keys, func(i, j int) bool {
+		c, e := compareValues(keys[i], keys[j])
+		if e != nil && err == nil {
+			err = e
+		}
+		return c < 0
+	})
+	if err != nil {
+		return mv, nil, fmt.Errorf("keys of %s: %w", mv.Type(), ErrNotOrdered)
+	}
+	return mv, keys, nil
+}
@@ -0,0 +1,41 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"b": 2, "c": 3, "a": 1}
+
+	keys, err := Keys(m, true)
+	if correct := []string{"a", "b", "c"}; !reflect.DeepEqual(keys, correct) || err != nil {
+		t.Errorf("[slice]: Keys test failed, expecting %v, got %v, err %v", correct, keys, err)
+	}
+	values, err := Values(m, true)
+	if correct := []int{1, 2, 3}; !reflect.DeepEqual(values, correct) || err != nil {
+		t.Errorf("[slice]: Values test failed, expecting %v, got %v, err %v", correct, values, err)
+	}
+
+	// unsorted, only the content is guaranteed
+	keys, err = Keys(m)
+	if k, ok := keys.([]string); !ok || err != nil {
+		t.Errorf("[slice]: Keys test failed, expecting []string, got %T, err %v", keys, err)
+	} else if sort.Strings(k); !reflect.DeepEqual(k, []string{"a", "b", "c"}) {
+		t.Errorf("[slice]: Keys test failed, expecting [a b c], got %v", k)
+	}
+
+	keys, err = Keys(map[int]bool{})
+	if correct := []int{}; !reflect.DeepEqual(keys, correct) || err != nil {
+		t.Errorf("[slice]: Keys test failed, expecting %v, got %v, err %v", correct, keys, err)
+	}
+
+	if _, err := Keys([]int{1}); !errors.Is(err, ErrNotMap) {
+		t.Errorf("[slice]: Keys test failed, expecting %v, got %v", ErrNotMap, err)
+	}
+	if _, err := Values(map[struct{ A int }]int{{1}: 1, {2}: 2}, true); !errors.Is(err, ErrNotOrdered) {
+		t.Errorf("[slice]: Values test failed, expecting %v, got %v", ErrNotOrdered, err)
+	}
+}
@@ -0,0 +1,137 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InterleavePolicy decides what Interleave does when a slice runs out of elements
+type InterleavePolicy int
+
+const (
+	// InterleaveLongest skip exhausted slices and go on with the others
+	InterleaveLongest InterleavePolicy = iota
+	// InterleaveShortest stop as soon as one slice is exhausted
+	InterleaveShortest
+	// InterleavePad go on with the zero value in place of exhausted slices
+	InterleavePad
+)
+
+// Interleave merge slices of the same type by taking one element of each in turn,
+// eg: [a1 a2 a3] [b1] becomes [a1 b1 a2 a3]
+func Interleave(slices ...interface{}) (interface{}, error) {
+	return InterleaveWith(InterleaveLongest, slices...)
+}
+
+// InterleaveWith like Interleave, with policy deciding how slices of different length are handled
+func InterleaveWith(policy InterleavePolicy, slices ...interface{}) (interface{}, error) {
+	if len(slices) == 0 {
+		return nil, nil
+	}
+
+	vals := make([]reflect.Value, len(slices))
+	min, max, total := -1, 0, 0
+	for i, s := range slices {
+		sv := reflect.ValueOf(s)
+		if !isSlice(sv) {
+			return nil, notSlice(fmt.Sprintf("slices[%d]", i), sv)
+		}
+		if i > 0 && sv.Type() != vals[0].Type() {
+			return nil, notSameType(fmt.Sprintf("slices[%d]", i), sv.Type(), vals[0].Type())
+		}
+		vals[i] = sv
+		if min < 0 || sv.Len() < min {
+			min = sv.Len()
+		}
+		if sv.Len() > max {
+			max = sv.Len()
+		}
+		total += sv.Len()
+	}
+
+	rounds := max
+	switch policy {
+	case InterleaveShortest:
+		rounds = min
+		total = min * len(vals)
+	case InterleavePad:
+		total = max * len(vals)
+	}
+
+	zero := reflect.Zero(vals[0].Type().Elem())
+	tmp := reflect.MakeSlice(vals[0].Type(), 0, total)
+	for i := 0; i < rounds; i++ {
+		for _, v := range vals {
+			if i < v.Len() {
+				tmp = reflect.Append(tmp, v.Index(i))
+			} else if policy == InterleavePad {
+				tmp = reflect.Append(tmp, zero)
+			}
+		}
+	}
+
+	return tmp.Interface(), nil
+}
+
+// MergeSorted merge the sorted slices a and b of the same type into a new
+// sorted slice in O(n), eg: to combine sorted directory listings without
+// sorting again. Elements are compared by the optional less, or by their
+// natural order if they are of an ordered kind. Equal elements of a come
+// first. With dedupe, only the first of equal elements is kept.
+func MergeSorted(a, b interface{}, dedupe bool, less ...func(x, y interface{}) bool) (interface{}, error) {
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+
+	for i, v := range []reflect.Value{av, bv} {
+		if !isSlice(v) {
+			return nil, notSlice([]string{"a", "b"}[i], v)
+		}
+	}
+
+	if av.Type() != bv.Type() {
+		return nil, notSameType("b", bv.Type(), av.Type())
+	}
+
+	var err error
+	lessFn := func(x, y reflect.Value) bool {
+		if len(less) > 0 {
+			return less[0](x.Interface(), y.Interface())
+		}
+		c, e := compareValues(x, y)
+		if e != nil && err == nil {
+			err = e
+		}
+		return c < 0
+	}
+
+	tmp := reflect.MakeSlice(av.Type(), 0, av.Len()+bv.Len())
+	add := func(v reflect.Value) {
+		// sorted input: an element equal to the last one is not less than it
+		if dedupe && tmp.Len() > 0 && !lessFn(tmp.Index(tmp.Len()-1), v) {
+			return
+		}
+		tmp = reflect.Append(tmp, v)
+	}
+
+	i, j := 0, 0
+	for i < av.Len() && j < bv.Len() {
+		if lessFn(bv.Index(j), av.Index(i)) {
+			add(bv.Index(j))
+			j++
+		} else {
+			add(av.Index(i))
+			i++
+		}
+	}
+	for ; i < av.Len(); i++ {
+		add(av.Index(i))
+	}
+	for ; j < bv.Len(); j++ {
+		add(bv.Index(j))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return tmp.Interface(), nil
+}
@@ -0,0 +1,259 @@
+package slice
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ChangeKind the kind of a Change
+type ChangeKind int
+
+const (
+	// Added an element was inserted
+	Added ChangeKind = iota
+	// Removed an element was deleted
+	Removed
+	// Replaced an element was overwritten with another value
+	Replaced
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Removed:
+		return "removed"
+	case Replaced:
+		return "replaced"
+	}
+	return "added"
+}
+
+// Change a change of an ObservableSlice. Index is the position of the element
+// in the slice after the mutation, or before it for removals. Old is nil for
+// additions and New for removals.
+type Change struct {
+	Kind  ChangeKind
+	Index int
+	Old   interface{}
+	New   interface{}
+}
+
+// ObservableSlice wrap a slice to notify subscribers of the changes made
+// through it, eg: to invalidate caches built from a mirror list. It is safe
+// for concurrent use. Subscribers are called synchronously after each
+// mutation, in the order they subscribed, and must not mutate the slice.
+type ObservableSlice struct {
+	mu   sync.Mutex
+	src  interface{}
+	sv   reflect.Value
+	subs map[int]func([]Change)
+	next int
+}
+
+// NewObservableSlice takes a pointer to slice as src and observe it. Changes
+// made to src directly, bypassing the ObservableSlice, are not reported.
+func NewObservableSlice(src interface{}) (*ObservableSlice, error) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return nil, notPointer("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	return &ObservableSlice{src: src, sv: sv, subs: make(map[int]func([]Change))}, nil
+}
+
+// Subscribe call fn with the changes of every mutation until cancel is called
+func (o *ObservableSlice) Subscribe(fn func([]Change)) (cancel func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	id := o.next
+	o.next++
+	o.subs[id] = fn
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		delete(o.subs, id)
+	}
+}
+
+// maxDiffCells the largest len(old)*len(new) Do diffs, beyond it the changes
+// are found position by position
+const maxDiffCells = 1 << 22
+
+// Do call fn with the pointer to the observed slice, so that any function of
+// this package can mutate it, eg: o.Do(func(p interface{}) error { return Unique(p) }),
+// and notify the subscribers of what changed, even if fn failed halfway.
+// The changes are found by diffing the slice before and after fn, see Diff.
+// When the product of both lengths exceeds 4M, element i is compared to
+// element i instead, which is linear but reports a shift as replacements.
+func (o *ObservableSlice) Do(fn func(src interface{}) error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old, err := Clone(o.sv.Interface())
+	if err != nil {
+		return err
+	}
+	ferr := fn(o.src)
+
+	ov := reflect.ValueOf(old)
+	if int64(ov.Len())*int64(o.sv.Len()) > maxDiffCells {
+		o.notify(positionalChanges(ov, o.sv))
+		return ferr
+	}
+	edits, err := Diff(old, o.sv.Interface())
+	if err != nil {
+		return err
+	}
+	o.notify(changesOf(edits))
+	return ferr
+}
+
+// notify call the subscribers in order with changes, if any, o.mu held
+func (o *ObservableSlice) notify(changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	ids := make([]int, 0, len(o.subs))
+	for id := range o.subs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		o.subs[id](changes)
+	}
+}
+
+// Append append values to the observed slice, see AppendN
+func (o *ObservableSlice) Append(values ...interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := o.sv.Len()
+	err := AppendN(o.src, values...)
+	var changes []Change
+	for i := n; i < o.sv.Len(); i++ {
+		changes = append(changes, Change{Kind: Added, Index: i, New: o.sv.Index(i).Interface()})
+	}
+	o.notify(changes)
+	return err
+}
+
+// Remove remove element from the observed slice, see RemoveN
+func (o *ObservableSlice) Remove(element interface{}) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old := make([]interface{}, o.sv.Len())
+	for i := range old {
+		old[i] = o.sv.Index(i).Interface()
+	}
+	n, err := RemoveN(o.src, element)
+
+	// what is left is a subsequence of old, the elements skipped were removed
+	var changes []Change
+	j := 0
+	for i, v := range old {
+		if j < o.sv.Len() && reflect.DeepEqual(v, o.sv.Index(j).Interface()) {
+			j++
+			continue
+		}
+		changes = append(changes, Change{Kind: Removed, Index: i, Old: v})
+	}
+	o.notify(changes)
+	return n, err
+}
+
+// Set set the element at index i of the observed slice to value
+func (o *ObservableSlice) Set(i int, value interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if i < 0 || i >= o.sv.Len() {
+		return ErrOutOfRange
+	}
+	et := o.sv.Type().Elem()
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		vv = reflect.Zero(et)
+	}
+	if !vv.Type().AssignableTo(et) {
+		return notSameType("value", vv.Type(), et)
+	}
+	old := o.sv.Index(i).Interface()
+	o.sv.Index(i).Set(vv)
+	if !reflect.DeepEqual(old, o.sv.Index(i).Interface()) {
+		o.notify([]Change{{Kind: Replaced, Index: i, Old: old, New: o.sv.Index(i).Interface()}})
+	}
+	return nil
+}
+
+// Snapshot a copy of the observed slice
+func (o *ObservableSlice) Snapshot() interface{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tmp, _ := Clone(o.sv.Interface())
+	return tmp
+}
+
+// changesOf turn an edit script into changes, a deletion followed by an
+// insertion at the same place being a replacement
+func changesOf(edits []Edit) []Change {
+	var changes []Change
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Keep {
+			i++
+			continue
+		}
+		var dels, ins []Edit
+		for ; i < len(edits) && edits[i].Op != Keep; i++ {
+			if edits[i].Op == Delete {
+				dels = append(dels, edits[i])
+			} else {
+				ins = append(ins, edits[i])
+			}
+		}
+		n := len(dels)
+		if len(ins) < n {
+			n = len(ins)
+		}
+		for j := 0; j < n; j++ {
+			changes = append(changes, Change{Kind: Replaced, Index: ins[j].NewIndex, Old: dels[j].Value, New: ins[j].Value})
+		}
+		for _, e := range dels[n:] {
+			changes = append(changes, Change{Kind: Removed, Index: e.OldIndex, Old: e.Value})
+		}
+		for _, e := range ins[n:] {
+			changes = append(changes, Change{Kind: Added, Index: e.NewIndex, New: e.Value})
+		}
+	}
+	return changes
+}
+
+// positionalChanges the changes turning old into new comparing the elements at
+// the same index, the extra elements being added or removed
+func positionalChanges(old, new reflect.Value) []Change {
+	var changes []Change
+	n := old.Len()
+	if new.Len() < n {
+		n = new.Len()
+	}
+	for i := 0; i < n; i++ {
+		if ov, nv := old.Index(i).Interface(), new.Index(i).Interface(); !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, Change{Kind: Replaced, Index: i, Old: ov, New: nv})
+		}
+	}
+	for i := n; i < old.Len(); i++ {
+		changes = append(changes, Change{Kind: Removed, Index: i, Old: old.Index(i).Interface()})
+	}
+	for i := n; i < new.Len(); i++ {
+		changes = append(changes, Change{Kind: Added, Index: i, New: new.Index(i).Interface()})
+	}
+	return changes
+}
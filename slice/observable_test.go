@@ -0,0 +1,66 @@
+package slice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestObservableSlice(t *testing.T) {
+	src := []int{1, 2, 1, 3}
+	o, err := NewObservableSlice(&src)
+	if err != nil {
+		t.Fatalf("[slice]: NewObservableSlice test failed with %s", err)
+	}
+	var got []Change
+	o.Subscribe(func(changes []Change) {
+		got = changes
+	})
+
+	tests := []struct {
+		name    string
+		fn      func() error
+		correct []Change
+	}{
+		{"Append", func() error { return o.Append(4, 5) },
+			[]Change{{Added, 4, nil, 4}, {Added, 5, nil, 5}}},
+		{"Remove", func() error { _, err := o.Remove(1); return err },
+			[]Change{{Removed, 0, 1, nil}, {Removed, 2, 1, nil}}},
+		{"Set", func() error { return o.Set(1, 9) },
+			[]Change{{Replaced, 1, 3, 9}}},
+		{"Set unchanged", func() error { return o.Set(1, 9) }, nil},
+		{"Do", func() error {
+			return o.Do(func(p interface{}) error {
+				s := p.(*[]int)
+				*s = append((*s)[1:], 7)
+				return nil
+			})
+		}, []Change{{Removed, 0, 2, nil}, {Added, 3, nil, 7}}},
+	}
+	for _, tc := range tests {
+		got = nil
+		if err := tc.fn(); err != nil || !reflect.DeepEqual(got, tc.correct) {
+			t.Errorf("[slice]: ObservableSlice %s test failed, expecting %v, got %v, err %v", tc.name, tc.correct, got, err)
+		}
+	}
+}
+
+func TestObservableSliceLarge(t *testing.T) {
+	src := make([]int, 3000)
+	o, _ := NewObservableSlice(&src)
+	var got []Change
+	o.Subscribe(func(changes []Change) {
+		got = changes
+	})
+
+	// too large to diff, compared position by position
+	err := o.Do(func(p interface{}) error {
+		s := p.(*[]int)
+		(*s)[10] = 1
+		*s = append(*s, 2)
+		return nil
+	})
+	correct := []Change{{Replaced, 10, 0, 1}, {Added, 3000, nil, 2}}
+	if err != nil || !reflect.DeepEqual(got, correct) {
+		t.Errorf("[slice]: ObservableSlice test failed, expecting %v, got %v, err %v", correct, got, err)
+	}
+}
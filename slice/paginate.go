@@ -0,0 +1,53 @@
+package slice
+
+import "reflect"
+
+// Page the metadata of a page returned by Paginate
+type Page struct {
+	// Page the 1-based number of the page
+	Page    int
+	PerPage int
+	// Total the number of elements in the source slice
+	Total int
+	// Pages the number of pages
+	Pages int
+}
+
+// HasNext whether there is a page after this one
+func (p Page) HasNext() bool {
+	return p.Page < p.Pages
+}
+
+// HasPrev whether there is a page before this one
+func (p Page) HasPrev() bool {
+	return p.Page > 1
+}
+
+// Paginate return a new slice holding the 1-based page of src with perPage elements
+// per page, along with the page metadata. ErrOutOfRange is returned with an empty
+// slice if page or perPage is smaller than 1 or page is after the last page.
+func Paginate(src interface{}, page, perPage int) (interface{}, Page, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, Page{}, notSlice("src", sv)
+	}
+
+	p := Page{Page: page, PerPage: perPage, Total: sv.Len()}
+	empty := reflect.MakeSlice(sv.Type(), 0, 0).Interface()
+
+	if perPage < 1 {
+		return empty, p, ErrOutOfRange
+	}
+
+	p.Pages = (p.Total + perPage - 1) / perPage
+
+	// an empty slice still has an (empty) first page
+	if page < 1 || (page > p.Pages && page != 1) {
+		return empty, p, ErrOutOfRange
+	}
+
+	start := clamp((page-1)*perPage, p.Total)
+	end := clamp(start+perPage, p.Total)
+
+	return subSlice(sv, start, end), p, nil
+}
@@ -0,0 +1,64 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Product return the cartesian product of slices, every combination taking
+// one element of each slice in order. The slices can be of different types.
+func Product(slices ...interface{}) ([][]interface{}, error) {
+	var res [][]interface{}
+	err := ProductEach(func(c []interface{}) bool {
+		tmp := make([]interface{}, len(c))
+		copy(tmp, c)
+		res = append(res, tmp)
+		return true
+	}, slices...)
+	return res, err
+}
+
+// ProductEach call fn with every combination of the cartesian product of slices
+// without building the whole product in memory. Returning false from fn stops
+// the iteration. The combination passed to fn is reused, copy it to keep it.
+func ProductEach(fn func([]interface{}) bool, slices ...interface{}) error {
+	vals := make([]reflect.Value, len(slices))
+	for i, s := range slices {
+		sv := reflect.ValueOf(s)
+		if !isSlice(sv) {
+			return notSlice(fmt.Sprintf("slices[%d]", i), sv)
+		}
+		if sv.Len() == 0 {
+			// the product with an empty set is empty
+			return nil
+		}
+		vals[i] = sv
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+
+	idx := make([]int, len(vals))
+	c := make([]interface{}, len(vals))
+	for {
+		for i, v := range vals {
+			c[i] = v.Index(idx[i]).Interface()
+		}
+		if !fn(c) {
+			return nil
+		}
+
+		// increase the indexes like an odometer, the last slice changes fastest
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < vals[i].Len() {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return nil
+		}
+	}
+}
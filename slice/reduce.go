@@ -0,0 +1,67 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Reduce fold src into one value: fn is called with the accumulator, initial
+// at first, and every element of src in order, and returns the next
+// accumulator. The last one is returned.
+func Reduce(src interface{}, fn func(acc, v interface{}) interface{}, initial interface{}) (interface{}, error) {
+	acc := initial
+	err := scan(src, fn, initial, func(_ int, v interface{}) error {
+		acc = v
+		return nil
+	})
+	return acc, err
+}
+
+// Scan return the running accumulations of Reduce as a new slice of the type
+// of initial, eg: the cumulative sizes of files:
+//
+//	Scan([]int64{10, 20, 5}, func(acc, v interface{}) interface{} { return acc.(int64) + v.(int64) }, int64(0))
+//
+// returns []int64{10, 30, 35}. fn must return values of the type of initial.
+func Scan(src interface{}, fn func(acc, v interface{}) interface{}, initial interface{}) (interface{}, error) {
+	iv := reflect.ValueOf(initial)
+	if !iv.IsValid() {
+		return nil, notSameType("initial", nil, nil)
+	}
+
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	tmp := reflect.MakeSlice(reflect.SliceOf(iv.Type()), sv.Len(), sv.Len())
+	err := scan(src, fn, initial, func(i int, v interface{}) error {
+		vv := reflect.ValueOf(v)
+		if !vv.IsValid() || !vv.Type().AssignableTo(iv.Type()) {
+			return notSameType(fmt.Sprintf("fn(src[%d])", i), typeOf(vv), iv.Type())
+		}
+		tmp.Index(i).Set(vv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tmp.Interface(), nil
+}
+
+// scan call emit with every accumulation of fn over src
+func scan(src interface{}, fn func(acc, v interface{}) interface{}, initial interface{}, emit func(i int, acc interface{}) error) error {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	acc := initial
+	for i := 0; i < sv.Len(); i++ {
+		acc = fn(acc, sv.Index(i).Interface())
+		if err := emit(i, acc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
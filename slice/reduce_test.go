@@ -0,0 +1,44 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReduceScan(t *testing.T) {
+	add := func(acc, v interface{}) interface{} { return acc.(int64) + v.(int64) }
+	join := func(acc, v interface{}) interface{} { return acc.(string) + v.(string) }
+	tests := []struct {
+		src     interface{}
+		fn      func(acc, v interface{}) interface{}
+		initial interface{}
+		reduced interface{}
+		scanned interface{}
+	}{
+		{[]int64{10, 20, 5}, add, int64(0), int64(35), []int64{10, 30, 35}},
+		{[]int64{}, add, int64(7), int64(7), []int64{}},
+		{[2]string{"a", "b"}, join, ">", ">ab", []string{">a", ">ab"}},
+	}
+	for _, tc := range tests {
+		res, err := Reduce(tc.src, tc.fn, tc.initial)
+		if !reflect.DeepEqual(res, tc.reduced) || err != nil {
+			t.Errorf("[slice]: Reduce test failed, expecting %v, got %v, err %v", tc.reduced, res, err)
+		}
+		res, err = Scan(tc.src, tc.fn, tc.initial)
+		if !reflect.DeepEqual(res, tc.scanned) || err != nil {
+			t.Errorf("[slice]: Scan test failed, expecting %v, got %v, err %v", tc.scanned, res, err)
+		}
+	}
+
+	toInt := func(acc, v interface{}) interface{} { return 1 }
+	if _, err := Scan([]int64{1}, toInt, int64(0)); !errors.Is(err, ErrNotSameType) {
+		t.Errorf("[slice]: Scan test failed, expecting %v, got %v", ErrNotSameType, err)
+	}
+	if _, err := Scan([]int64{1}, add, nil); !errors.Is(err, ErrNotSameType) {
+		t.Errorf("[slice]: Scan test failed, expecting %v, got %v", ErrNotSameType, err)
+	}
+	if _, err := Reduce(1, add, int64(0)); !errors.Is(err, ErrNotSlice) {
+		t.Errorf("[slice]: Reduce test failed, expecting %v, got %v", ErrNotSlice, err)
+	}
+}
@@ -0,0 +1,155 @@
+package slice
+
+import (
+	"reflect"
+	"sync"
+)
+
+// PushBounded takes a pointer to slice as source and appends value to it,
+// dropping the oldest elements so that it never holds more than max elements.
+// The backing array is reused, so the slice doesn't grow beyond max.
+func PushBounded(src interface{}, max int, value interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	if max <= 0 {
+		return ErrOutOfRange
+	}
+
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		vv = reflect.Zero(sv.Type().Elem())
+	}
+	if !vv.Type().AssignableTo(sv.Type().Elem()) {
+		return notSameType("value", vv.Type(), sv.Type().Elem())
+	}
+
+	l := sv.Len()
+	if l < max {
+		sv.Set(reflect.Append(sv, vv))
+		return nil
+	}
+
+	// shift the newest max-1 elements to the front and put value last
+	reflect.Copy(sv, sv.Slice(l-max+1, l))
+	sv.SetLen(max)
+	sv.Index(max - 1).Set(vv)
+	return nil
+}
+
+// RingBuffer a fixed-capacity buffer keeping the last pushed values, the
+// oldest value is overwritten once it is full. It is safe for concurrent use,
+// eg: keeping the last N events of a long-running watcher.
+type RingBuffer struct {
+	mu    sync.Mutex
+	buf   []interface{}
+	start int
+	n     int
+}
+
+// NewRingBuffer create a RingBuffer holding up to capacity values
+func NewRingBuffer(capacity int) (*RingBuffer, error) {
+	if capacity <= 0 {
+		return nil, ErrOutOfRange
+	}
+	return &RingBuffer{buf: make([]interface{}, capacity)}, nil
+}
+
+// Push add v to the buffer, returning the value it evicted if the buffer was full
+func (r *RingBuffer) Push(v interface{}) (evicted interface{}, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = v
+		r.n++
+		return nil, false
+	}
+
+	evicted = r.buf[r.start]
+	r.buf[r.start] = v
+	r.start = (r.start + 1) % len(r.buf)
+	return evicted, true
+}
+
+// Len the number of values in the buffer
+func (r *RingBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.n
+}
+
+// Cap the maximum number of values the buffer holds
+func (r *RingBuffer) Cap() int {
+	return len(r.buf)
+}
+
+// Items a copy of the values in the buffer, oldest first
+func (r *RingBuffer) Items() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]interface{}, r.n)
+	for i := range items {
+		items[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+	return items
+}
+
+// CopyTo takes a pointer to slice as dst and sets it to the values in the
+// buffer, oldest first. The values must be assignable to the element type of dst.
+func (r *RingBuffer) CopyTo(dst interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() == reflect.Ptr {
+		dv = dv.Elem()
+	} else {
+		return notPointer("dst", dv)
+	}
+
+	if dv.Kind() == reflect.Array {
+		return fixedLength("dst", dv)
+	}
+
+	if !isSlice(dv) {
+		return notSlice("dst", dv)
+	}
+
+	items := r.Items()
+	out := reflect.MakeSlice(dv.Type(), len(items), len(items))
+	for i, item := range items {
+		iv := reflect.ValueOf(item)
+		if !iv.IsValid() {
+			continue
+		}
+		if !iv.Type().AssignableTo(dv.Type().Elem()) {
+			return notSameType("dst", iv.Type(), dv.Type().Elem())
+		}
+		out.Index(i).Set(iv)
+	}
+	dv.Set(out)
+	return nil
+}
+
+// Reset empty the buffer
+func (r *RingBuffer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.buf {
+		r.buf[i] = nil
+	}
+	r.start = 0
+	r.n = 0
+}
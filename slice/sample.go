@@ -0,0 +1,53 @@
+package slice
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+)
+
+// SampleWeighted return an element of src chosen at random with a
+// probability proportional to its weight in weights, eg: to pick a mirror.
+// There must be one weight per element, none negative, NaN or infinite, and
+// at least one positive. Elements of weight 0 are never chosen.
+// It uses the default source of math/rand.
+func SampleWeighted(src interface{}, weights []float64) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	if sv.Len() == 0 {
+		return nil, ErrEmpty
+	}
+	if len(weights) != sv.Len() {
+		return nil, fmt.Errorf("%w: %d weights for %d elements", ErrInvalidWeight, len(weights), sv.Len())
+	}
+
+	total := 0.0
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("%w: weights[%d] is %v", ErrInvalidWeight, i, w)
+		}
+		total += w
+	}
+	if total == 0 || math.IsInf(total, 0) {
+		return nil, fmt.Errorf("%w: the weights sum to %v", ErrInvalidWeight, total)
+	}
+
+	r := rand.Float64() * total
+	last := 0
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if r < w {
+			return sv.Index(i).Interface(), nil
+		}
+		r -= w
+		last = i
+	}
+	// rounding errors may leave r just above the last weight
+	return sv.Index(last).Interface(), nil
+}
@@ -0,0 +1,46 @@
+package slice
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSampleWeighted(t *testing.T) {
+	src := []string{"a", "b", "c"}
+	counts := make(map[interface{}]int)
+	for i := 0; i < 1000; i++ {
+		v, err := SampleWeighted(src, []float64{1, 0, 3})
+		if err != nil {
+			t.Fatalf("[slice]: SampleWeighted test failed with %v", err)
+		}
+		counts[v]++
+	}
+	if counts["b"] != 0 || counts["a"] == 0 || counts["c"] <= counts["a"] {
+		t.Errorf("[slice]: SampleWeighted test failed, expecting b never chosen and c more often than a, got %v", counts)
+	}
+
+	if v, err := SampleWeighted([1]int{7}, []float64{0.5}); v != 7 || err != nil {
+		t.Errorf("[slice]: SampleWeighted test failed, expecting 7, got %v, err %v", v, err)
+	}
+
+	tests := []struct {
+		src     interface{}
+		weights []float64
+		err     error
+	}{
+		{[]int{}, nil, ErrEmpty},
+		{[]int{1, 2}, []float64{1}, ErrInvalidWeight},
+		{[]int{1, 2}, []float64{1, -1}, ErrInvalidWeight},
+		{[]int{1}, []float64{math.NaN()}, ErrInvalidWeight},
+		{[]int{1}, []float64{math.Inf(1)}, ErrInvalidWeight},
+		{[]int{1, 2}, []float64{math.MaxFloat64, math.MaxFloat64}, ErrInvalidWeight},
+		{[]int{1, 2}, []float64{0, 0}, ErrInvalidWeight},
+		{"ab", []float64{1, 1}, ErrNotSlice},
+	}
+	for _, tc := range tests {
+		if _, err := SampleWeighted(tc.src, tc.weights); !errors.Is(err, tc.err) {
+			t.Errorf("[slice]: SampleWeighted test failed for %v and %v, expecting %v, got %v", tc.src, tc.weights, tc.err, err)
+		}
+	}
+}
@@ -1,15 +1,27 @@
 package slice
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
 	"reflect"
+	"strings"
 )
 
 var (
-	ErrNotSlice    = errors.New("Not a slice")
-	ErrNotPointer  = errors.New("Not a pointer type")
-	ErrNotSameType = errors.New("Not the same type")
+	ErrNotSlice      = errors.New("Not a slice")
+	ErrNotPointer    = errors.New("Not a pointer type")
+	ErrNotSameType   = errors.New("Not the same type")
+	ErrOutOfRange    = errors.New("Out of range")
+	ErrNotChan       = errors.New("Not a channel")
+	ErrNotNumeric    = errors.New("Not a numeric type")
+	ErrEmpty         = errors.New("Empty slice")
+	ErrDuplicate     = errors.New("Duplicate key")
+	ErrNotOrdered    = errors.New("Not an ordered type")
+	ErrFixedLength   = errors.New("Can't change the length of an array")
+	ErrInvalidWeight = errors.New("Invalid weight")
+	ErrOverflow      = errors.New("Overflow")
+	ErrNotMap        = errors.New("Not a map")
 )
 
 // Contains takes a source Slice/Array and an element that can be slice/Array
@@ -29,7 +41,7 @@ func Contains(src interface{}, element interface{}) (bool, error) {
 	}
 
 	if !isSlice(sv) {
-		return false, ErrNotSlice
+		return false, notSlice("src", sv)
 	}
 
 	if ev.Kind() == reflect.Slice || ev.Kind() == reflect.Array {
@@ -52,36 +64,113 @@ func Contains(src interface{}, element interface{}) (bool, error) {
 	return false, nil
 }
 
-func shortest(src interface{}) (dst interface{}, err error) {
+// Shortest find the shortest element in src: strings, slices, arrays and maps
+// are measured by length, numbers by value. On a tie the first one wins.
+// ErrNotSameType is returned if the elements are of different kinds.
+func Shortest(src interface{}) (interface{}, error) {
+	return extreme(src, -1)
+}
+
+// Longest find the longest element in src, see Shortest
+func Longest(src interface{}) (interface{}, error) {
+	return extreme(src, 1)
+}
+
+func extreme(src interface{}, want int) (interface{}, error) {
 	sv := reflect.ValueOf(src)
 
-	if !isSlice(sv) {
-		return dst, ErrNotSlice
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	if sv.Len() == 0 {
+		return nil, ErrEmpty
 	}
 
+	var dst, dm reflect.Value
 	for i := 0; i < sv.Len(); i++ {
-		if dst == nil {
-			dst = sv.Index(i).Interface()
+		v := sv.Index(i)
+		// elements of []interface{} carry their dynamic type
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		m, err := measure(v)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			dst, dm = v, m
 			continue
 		}
-		if sv.Index(i).Kind() == reflect.String {
-			if sv.Index(i).Len() < len(reflect.ValueOf(dst).String()) {
-				dst = sv.Index(i).Interface()
-			}
+		if v.Kind() != dst.Kind() {
+			return nil, notSameType(fmt.Sprintf("src[%d]", i), v.Type(), dst.Type())
 		}
-		if sv.Index(i).Kind() == reflect.Int {
-			if sv.Index(i).Int() < reflect.ValueOf(dst).Int() {
-				dst = sv.Index(i).Interface()
-			}
+		c, err := compareValues(m, dm)
+		if err != nil {
+			return nil, err
+		}
+		if c == want {
+			dst, dm = v, m
 		}
 	}
-	return dst, err
+	return dst.Interface(), nil
+}
+
+// measure the value Shortest and Longest compare v by
+func measure(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return reflect.ValueOf(v.Len()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v, nil
+	}
+	return v, ErrNotOrdered
 }
 
 // ShortestString find the shortest string in string slice
 func ShortestString(src []string) (string, error) {
-	s, e := shortest(src)
-	return s.(string), e
+	s, e := Shortest(src)
+	if e != nil {
+		return "", e
+	}
+	return s.(string), nil
+}
+
+// LongestString find the longest string in string slice
+func LongestString(src []string) (string, error) {
+	s, e := Longest(src)
+	if e != nil {
+		return "", e
+	}
+	return s.(string), nil
+}
+
+// Join stringify every element of src and join them with sep.
+// fmt.Stringer and error are honored by fmt, os.FileInfo is printed by its name.
+func Join(src interface{}, sep string) (string, error) {
+	sv := reflect.ValueOf(src)
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return "", notSlice("src", sv)
+	}
+
+	var b strings.Builder
+	for i := 0; i < sv.Len(); i++ {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		switch v := sv.Index(i).Interface().(type) {
+		case string:
+			b.WriteString(v)
+		case os.FileInfo:
+			b.WriteString(v.Name())
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String(), nil
 }
 
 // Remove takes a pointer to slice as source and an element that
@@ -90,6 +179,13 @@ func ShortestString(src []string) (string, error) {
 // It will remove the single element or elements in the provided
 // slice from the source slice
 func Remove(src interface{}, element interface{}) error {
+	_, err := RemoveN(src, element)
+	return err
+}
+
+// RemoveN remove like Remove and return the number of elements removed
+// from the source slice, 0 if nothing matched.
+func RemoveN(src interface{}, element interface{}) (int, error) {
 	sv := reflect.ValueOf(src)
 	// no need to reflect a reflect.Value again, will return a struct Kind()
 	var ev reflect.Value
@@ -103,20 +199,27 @@ func Remove(src interface{}, element interface{}) error {
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	} else {
-		return ErrNotPointer
+		return 0, notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return 0, fixedLength("src", sv)
 	}
 
 	if !isSlice(sv) {
-		return ErrNotSlice
+		return 0, notSlice("src", sv)
 	}
 
 	if isSlice(ev) {
+		n := 0
 		for i := 0; i < ev.Len(); i++ {
-			e := Remove(src, ev.Index(i))
+			m, e := RemoveN(src, ev.Index(i))
+			n += m
 			if e != nil {
-				return e
+				return n, e
 			}
 		}
+		return n, nil
 	} else {
 		if sv.Type().Elem().Kind() == ev.Kind() {
 			idx := []int{}
@@ -127,11 +230,10 @@ func Remove(src interface{}, element interface{}) error {
 			}
 			tmp := removeFromSlice(idx, sv)
 			sv.Set(tmp)
-			return nil
+			return len(idx), nil
 		}
-		return ErrNotSameType
+		return 0, notSameType("element", typeOf(ev), sv.Type().Elem())
 	}
-	return nil
 }
 
 // Unique remove the duplicated element from a slice
@@ -140,11 +242,15 @@ func Unique(src interface{}) error {
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	} else {
-		return ErrNotPointer
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
 	}
 
 	if !isSlice(sv) {
-		return ErrNotSlice
+		return notSlice("src", sv)
 	}
 
 	m := make(map[interface{}]struct{})
@@ -165,21 +271,97 @@ func Unique(src interface{}) error {
 	return nil
 }
 
+// UniqueLast remove the duplicated element from a slice like Unique, but keep
+// the last occurrence, eg: when later entries override earlier ones.
+func UniqueLast(src interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	m := make(map[interface{}]struct{})
+	idx := []int{}
+
+	for i := sv.Len() - 1; i >= 0; i-- {
+		k := genKey(sv.Index(i))
+		if _, ok := m[k]; ok {
+			idx = append(idx, i)
+		} else {
+			m[k] = struct{}{}
+		}
+	}
+
+	tmp := removeFromSlice(idx, sv)
+	sv.Set(tmp)
+
+	return nil
+}
+
+// IntersectOrder decides which side of IntersectOrderedBy dictates the order of the result
+type IntersectOrder int
+
+const (
+	// SrcOrder keep the elements in the order of src, like Intersect does
+	SrcOrder IntersectOrder = iota
+	// DstOrder reorder the elements like dst, eg: to follow a priority list
+	DstOrder
+)
+
 // Intersect find the common piece of two slice
 func Intersect(src interface{}, dst interface{}) error {
+	return IntersectOrderedBy(src, dst, SrcOrder)
+}
+
+// IntersectOrderedBy find the common piece of two slice like Intersect, in
+// the order of src or dst. With DstOrder, the duplicates of an element of src
+// are kept together at the position of its first occurrence in dst.
+func IntersectOrderedBy(src interface{}, dst interface{}, order IntersectOrder) error {
 	sv := reflect.ValueOf(src)
 	dv := reflect.ValueOf(dst)
 
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	} else {
-		return ErrNotPointer
+		return notPointer("src", sv)
 	}
 
-	for _, v := range []reflect.Value{sv, dv} {
-		if !isSlice(v) {
-			return ErrNotSlice
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	for i, v := range []reflect.Value{sv, dv} {
+		if !isSlice(v) && v.Kind() != reflect.Array {
+			return notSlice([]string{"src", "dst"}[i], v)
+		}
+	}
+
+	if order == DstOrder {
+		// the positions of every element of src, consumed in the order of dst
+		pos := make(map[interface{}][]int)
+		for i := 0; i < sv.Len(); i++ {
+			k := genKey(sv.Index(i))
+			pos[k] = append(pos[k], i)
+		}
+		tmp := reflect.MakeSlice(sv.Type(), 0, sv.Len())
+		for i := 0; i < dv.Len(); i++ {
+			k := genKey(dv.Index(i))
+			for _, j := range pos[k] {
+				tmp = reflect.Append(tmp, sv.Index(j))
+			}
+			delete(pos, k)
 		}
+		sv.Set(tmp)
+		return nil
 	}
 
 	m := make(map[interface{}]struct{})
@@ -209,11 +391,15 @@ func Concat(src interface{}, dst interface{}) error {
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	} else {
-		return ErrNotPointer
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
 	}
 
 	if !isSlice(sv) {
-		return ErrNotSlice
+		return notSlice("src", sv)
 	}
 
 	m := make(map[interface{}]struct{})
@@ -223,11 +409,14 @@ func Concat(src interface{}, dst interface{}) error {
 	}
 
 	if dv.Kind() == reflect.Slice {
+		// collect first, so that sv is reallocated at most once
+		vals := make([]reflect.Value, 0, dv.Len())
 		for j := 0; j < dv.Len(); j++ {
 			if _, ok := m[genKey(dv.Index(j))]; !ok {
-				sv.Set(reflect.Append(sv, dv.Index(j)))
+				vals = append(vals, dv.Index(j))
 			}
 		}
+		sv.Set(reflect.Append(sv, vals...))
 	} else {
 		if sv.Type().Elem().Kind() == dv.Kind() {
 			if _, ok := m[genKey(dv)]; !ok {
@@ -235,7 +424,7 @@ func Concat(src interface{}, dst interface{}) error {
 			}
 			return nil
 		}
-		return ErrNotSameType
+		return notSameType("dst", typeOf(dv), sv.Type().Elem())
 	}
 	return nil
 }
@@ -247,7 +436,7 @@ func Replace(src, old, new interface{}) error {
 	if sv.Kind() == reflect.Ptr {
 		sv = sv.Elem()
 	} else {
-		return ErrNotPointer
+		return notPointer("src", sv)
 	}
 
 	var ov reflect.Value
@@ -259,7 +448,7 @@ func Replace(src, old, new interface{}) error {
 	}
 
 	if sv.Type().Elem().Kind() != nv.Kind() {
-		return ErrNotSameType
+		return notSameType("new", typeOf(nv), sv.Type().Elem())
 	}
 
 	for i := 0; i < sv.Len(); i++ {
@@ -271,6 +460,80 @@ func Replace(src, old, new interface{}) error {
 	return nil
 }
 
+// ReplaceFunc takes a pointer to slice or array as source and replaces the elements
+// satisfying match with new, the first limit ones only if limit is not
+// negative, like strings.Replace. It returns the number of replaced elements.
+func ReplaceFunc(src interface{}, match func(interface{}) bool, new interface{}, limit int) (int, error) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return 0, notPointer("src", sv)
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return 0, notSlice("src", sv)
+	}
+
+	nv := reflect.ValueOf(new)
+	if !nv.IsValid() {
+		nv = reflect.Zero(sv.Type().Elem())
+	}
+	if !nv.Type().AssignableTo(sv.Type().Elem()) {
+		return 0, notSameType("new", nv.Type(), sv.Type().Elem())
+	}
+
+	n := 0
+	for i := 0; i < sv.Len() && (limit < 0 || n < limit); i++ {
+		if match(sv.Index(i).Interface()) {
+			sv.Index(i).Set(nv)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Rotate takes a pointer to slice or array as source and rotates it in place by n
+// positions, like ruby's Array#rotate!: positive n rotates left, negative n rotates right.
+func Rotate(src interface{}, n int) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	l := sv.Len()
+	if l == 0 {
+		return nil
+	}
+	n %= l
+	if n < 0 {
+		n += l
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// rotate by three reversals, no extra allocation
+	swap := reflect.Swapper(sv.Slice(0, l).Interface())
+	reverse := func(i, j int) {
+		for ; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	reverse(0, n-1)
+	reverse(n, l-1)
+	reverse(0, l-1)
+
+	return nil
+}
+
 // Flatten flatten slice of slices to one depth slice
 func Flatten(slice interface{}) (interface{}, error) {
 	sv := reflect.ValueOf(slice)
@@ -303,17 +566,34 @@ func Flatten(slice interface{}) (interface{}, error) {
 	return slice, nil
 }
 
-//genKey generate map key
-// currently support all fully comparable type and struct
-func genKey(v reflect.Value) interface{} {
-	k := v.Interface()
+// FlatMap call fn with every element of src and concatenate the slices it
+// returns into a new slice, without building the slice of slices Flatten
+// would need. fn must always return slices of the same type, the result is
+// nil if src is empty.
+func FlatMap(src interface{}, fn func(interface{}) interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
 
-	if v.Kind() == reflect.Struct {
-		b, _ := json.Marshal(k)
-		k = reflect.ValueOf(string(b)).Interface()
+	var tmp reflect.Value
+	for i := 0; i < sv.Len(); i++ {
+		rv := reflect.ValueOf(fn(sv.Index(i).Interface()))
+		if !isSlice(rv) {
+			return nil, notSlice(fmt.Sprintf("fn(src[%d])", i), rv)
+		}
+		if !tmp.IsValid() {
+			tmp = reflect.MakeSlice(rv.Type(), 0, rv.Len()*sv.Len())
+		} else if rv.Type() != tmp.Type() {
+			return nil, notSameType(fmt.Sprintf("fn(src[%d])", i), rv.Type(), tmp.Type())
+		}
+		tmp = reflect.AppendSlice(tmp, rv)
 	}
 
-	return k
+	if !tmp.IsValid() {
+		return nil, nil
+	}
+	return tmp.Interface(), nil
 }
 
 func isSlice(v reflect.Value) bool {
@@ -0,0 +1,90 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+var benchInts = func() []int {
+	s := make([]int, 100000)
+	for i := range s {
+		// values above 255 are not cached by the runtime and allocate when boxed
+		s[i] = i * 1000
+	}
+	return s
+}()
+
+func BenchmarkInterface(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		sum := 0
+		sv := reflect.ValueOf(benchInts)
+		for i := 0; i < sv.Len(); i++ {
+			sum += sv.Index(i).Interface().(int)
+		}
+	}
+}
+
+func BenchmarkEach(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		sum := 0
+		Each(benchInts, func(v reflect.Value) bool {
+			sum += int(v.Int())
+			return true
+		})
+	}
+}
+
+func BenchmarkEachInto(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		sum, v := 0, 0
+		EachInto(benchInts, &v, func(i int) bool {
+			sum += v
+			return true
+		})
+	}
+}
+
+func BenchmarkEachBatch(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		sum := 0
+		EachBatch(benchInts, 4096, func(batch reflect.Value) bool {
+			for _, v := range batch.Interface().([]int) {
+				sum += v
+			}
+			return true
+		})
+	}
+}
+
+var benchPaths = func() []string {
+	s := make([]string, 1000000)
+	for i := range s {
+		s[i] = fmt.Sprintf("/usr/share/%x/%d", (i*7919)%104729, i)
+	}
+	return s
+}()
+
+func BenchmarkSortSlice(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		s := append([]string(nil), benchPaths...)
+		b.StartTimer()
+		sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	}
+}
+
+func BenchmarkSortParallel(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		s := append([]string(nil), benchPaths...)
+		b.StartTimer()
+		SortParallel(s, func(i, j int) bool { return s[i] < s[j] }, runtime.NumCPU())
+	}
+}
@@ -0,0 +1,36 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatMap(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		fn      func(interface{}) interface{}
+		correct interface{}
+		err     error
+	}{
+		{[]string{"a b", "c"}, func(v interface{}) interface{} { return strings.Fields(v.(string)) }, []string{"a", "b", "c"}, nil},
+		{[2]int{1, 3}, func(v interface{}) interface{} { return []int{v.(int), v.(int) * 10} }, []int{1, 10, 3, 30}, nil},
+		{[]int{0, 2}, func(v interface{}) interface{} { return make([]int, v.(int)) }, []int{0, 0}, nil},
+		{[]int{}, func(v interface{}) interface{} { return []int{1} }, nil, nil},
+		{[]int{1}, func(v interface{}) interface{} { return v }, nil, ErrNotSlice},
+		{[]int{1, 2}, func(v interface{}) interface{} {
+			if v.(int) == 1 {
+				return []int{1}
+			}
+			return []string{"2"}
+		}, nil, ErrNotSameType},
+		{"ab", func(v interface{}) interface{} { return v }, nil, ErrNotSlice},
+	}
+	for i, tc := range tests {
+		res, err := FlatMap(tc.src, tc.fn)
+		if !reflect.DeepEqual(res, tc.correct) || !errors.Is(err, tc.err) {
+			t.Errorf("[slice]: FlatMap test %d failed, expecting %v, got %v, err %v", i, tc.correct, res, err)
+		}
+	}
+}
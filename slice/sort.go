@@ -0,0 +1,215 @@
+package slice
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// parallelThreshold below this length SortParallel is sort.Slice
+const parallelThreshold = 1 << 14
+
+// SortParallel sort src with less like sort.Slice does, using workers
+// goroutines: chunks of src are sorted concurrently and then merged, the
+// merges of a level running concurrently too. It pays off for slices of
+// millions of elements, eg: the paths of a recursive tree scan. Below
+// 16384 elements, or with less than 2 workers, it is sort.Slice. The sort
+// is not stable. less is only called with indexes of elements that are not
+// being moved, so it can index src like for sort.Slice.
+func SortParallel(src interface{}, less func(i, j int) bool, workers int) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+	if sv.Kind() == reflect.Array {
+		sv = sv.Slice(0, sv.Len())
+	}
+
+	n := sv.Len()
+	if n < parallelThreshold || workers < 2 {
+		sort.Slice(sv.Interface(), less)
+		return nil
+	}
+
+	size := (n + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += size {
+		hi := lo + size
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		// a swapper per chunk, the ones of struct and interface slices share a scratch value
+		swap := reflect.Swapper(sv.Slice(lo, hi).Interface())
+		go func(lo, hi int) {
+			defer wg.Done()
+			sort.Sort(&chunk{lo, hi - lo, less, swap})
+		}(lo, hi)
+	}
+	wg.Wait()
+
+	m := newMerger(sv)
+	sem := make(chan struct{}, workers)
+	for ; size < n; size *= 2 {
+		for lo := 0; lo+size < n; lo += 2 * size {
+			hi := lo + 2*size
+			if hi > n {
+				hi = n
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(lo, mid, hi int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				m.merge(lo, mid, hi, less)
+			}(lo, lo+size, hi)
+		}
+		// the merges of the next level read what this level wrote
+		wg.Wait()
+	}
+
+	return nil
+}
+
+// chunk a sort.Interface over src[off:off+n], swap indexing the chunk itself
+type chunk struct {
+	off, n int
+	less   func(i, j int) bool
+	swap   func(i, j int)
+}
+
+func (c *chunk) Len() int           { return c.n }
+func (c *chunk) Less(i, j int) bool { return c.less(c.off+i, c.off+j) }
+func (c *chunk) Swap(i, j int)      { c.swap(i, j) }
+
+// merger merges sorted runs of a slice through a buffer of the same length,
+// with typed fast paths for the common element types
+type merger struct {
+	put  func(k, i int)
+	back func(lo, hi int)
+}
+
+func newMerger(sv reflect.Value) *merger {
+	switch s := sv.Interface().(type) {
+	case []string:
+		buf := make([]string, len(s))
+		return &merger{
+			put:  func(k, i int) { buf[k] = s[i] },
+			back: func(lo, hi int) { copy(s[lo:hi], buf[lo:hi]) },
+		}
+	case []int:
+		buf := make([]int, len(s))
+		return &merger{
+			put:  func(k, i int) { buf[k] = s[i] },
+			back: func(lo, hi int) { copy(s[lo:hi], buf[lo:hi]) },
+		}
+	case []float64:
+		buf := make([]float64, len(s))
+		return &merger{
+			put:  func(k, i int) { buf[k] = s[i] },
+			back: func(lo, hi int) { copy(s[lo:hi], buf[lo:hi]) },
+		}
+	}
+
+	buf := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+	return &merger{
+		put:  func(k, i int) { buf.Index(k).Set(sv.Index(i)) },
+		back: func(lo, hi int) { reflect.Copy(sv.Slice(lo, hi), buf.Slice(lo, hi)) },
+	}
+}
+
+// merge the sorted runs src[lo:mid] and src[mid:hi]
+func (m *merger) merge(lo, mid, hi int, less func(i, j int) bool) {
+	// already in order
+	if !less(mid, mid-1) {
+		return
+	}
+
+	i, j, k := lo, mid, lo
+	for i < mid && j < hi {
+		if less(j, i) {
+			m.put(k, j)
+			j++
+		} else {
+			m.put(k, i)
+			i++
+		}
+		k++
+	}
+	for ; i < mid; i++ {
+		m.put(k, i)
+		k++
+	}
+	for ; j < hi; j++ {
+		m.put(k, j)
+		k++
+	}
+	m.back(lo, hi)
+}
+
+// SortUnique sort the slice src points to, of an ordered kind (integers,
+// floats, strings), in ascending order and remove the duplicates in the same
+// pass, without allocating a new slice. NaNs sort first and are deduplicated
+// together, like sort.Float64s orders them.
+func SortUnique(src interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	} else {
+		return notPointer("src", sv)
+	}
+
+	if sv.Kind() == reflect.Array {
+		return fixedLength("src", sv)
+	}
+
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	less := orderedLess(sv)
+	if less == nil {
+		return ErrNotOrdered
+	}
+	sort.Slice(sv.Interface(), less)
+
+	w := 0
+	for i := 0; i < sv.Len(); i++ {
+		if i > 0 && !less(w-1, i) {
+			continue
+		}
+		if w != i {
+			sv.Index(w).Set(sv.Index(i))
+		}
+		w++
+	}
+	sv.SetLen(w)
+
+	return nil
+}
+
+// orderedLess a less function over the elements of the slice sv, nil if they aren't of an ordered kind
+func orderedLess(sv reflect.Value) func(i, j int) bool {
+	switch sv.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(i, j int) bool { return sv.Index(i).Int() < sv.Index(j).Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(i, j int) bool { return sv.Index(i).Uint() < sv.Index(j).Uint() }
+	case reflect.Float32, reflect.Float64:
+		return func(i, j int) bool {
+			a, b := sv.Index(i).Float(), sv.Index(j).Float()
+			return a < b || (math.IsNaN(a) && !math.IsNaN(b))
+		}
+	case reflect.String:
+		return func(i, j int) bool { return sv.Index(i).String() < sv.Index(j).String() }
+	}
+	return nil
+}
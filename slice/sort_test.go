@@ -0,0 +1,63 @@
+package slice
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSortParallelStruct(t *testing.T) {
+	type item struct {
+		Key  int
+		Name string
+	}
+	s := make([]item, 200000)
+	for i := range s {
+		s[i] = item{rand.Intn(len(s)), "item"}
+	}
+	sum := 0
+	for _, v := range s {
+		sum += v.Key
+	}
+
+	err := SortParallel(s, func(i, j int) bool { return s[i].Key < s[j].Key }, 8)
+	sorted := sort.SliceIsSorted(s, func(i, j int) bool { return s[i].Key < s[j].Key })
+	sum1 := 0
+	for _, v := range s {
+		sum1 += v.Key
+	}
+	if err != nil || !sorted || sum1 != sum {
+		t.Errorf("[slice]: SortParallel test failed, expecting sorted elements summing to %d, got sorted %t, sum %d, err %v", sum, sorted, sum1, err)
+	}
+}
+
+func TestSortUnique(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		src     interface{}
+		correct interface{}
+		err     error
+	}{
+		{&[]int{3, 1, 3, 2, 1}, &[]int{1, 2, 3}, nil},
+		{&[]string{"b", "a", "b"}, &[]string{"a", "b"}, nil},
+		{&[]int{}, &[]int{}, nil},
+		{&[]uint8{7}, &[]uint8{7}, nil},
+		{&[]struct{}{{}, {}}, nil, ErrNotOrdered},
+		{&[2]int{2, 1}, nil, ErrFixedLength},
+		{[]int{2, 1}, nil, ErrNotPointer},
+	}
+	for _, tc := range tests {
+		err := SortUnique(tc.src)
+		if !errors.Is(err, tc.err) || (tc.correct != nil && !reflect.DeepEqual(tc.src, tc.correct)) {
+			t.Errorf("[slice]: SortUnique test failed, expecting %v, got %v, err %v", tc.correct, tc.src, err)
+		}
+	}
+
+	floats := []float64{2, nan, 1, nan, 2}
+	if err := SortUnique(&floats); err != nil || len(floats) != 3 || !math.IsNaN(floats[0]) || floats[1] != 1 || floats[2] != 2 {
+		t.Errorf("[slice]: SortUnique test failed, expecting [NaN 1 2], got %v, err %v", floats, err)
+	}
+}
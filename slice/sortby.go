@@ -0,0 +1,199 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Key a multi-criteria sort order, built with By and its Then methods, eg:
+//
+//	SortBy(infos, By("Name").ThenByDesc("Size").ThenBy("ModTime"))
+//
+// Each criterion names a struct field or a method without arguments, like
+// the ones of os.FileInfo, of the elements. The values must be of an ordered
+// kind, bool (false first), or have a Before method, like time.Time.
+type Key struct {
+	names []string
+	desc  []bool
+}
+
+// By start a Key sorting by name ascending
+func By(name string) *Key {
+	return (&Key{}).ThenBy(name)
+}
+
+// ByDesc start a Key sorting by name descending
+func ByDesc(name string) *Key {
+	return (&Key{}).ThenByDesc(name)
+}
+
+// ThenBy break the ties of the previous criteria by name ascending
+func (k *Key) ThenBy(name string) *Key {
+	return k.then(name, false)
+}
+
+// ThenByDesc break the ties of the previous criteria by name descending
+func (k *Key) ThenByDesc(name string) *Key {
+	return k.then(name, true)
+}
+
+func (k *Key) then(name string, desc bool) *Key {
+	return &Key{
+		names: append(append([]string(nil), k.names...), name),
+		desc:  append(append([]bool(nil), k.desc...), desc),
+	}
+}
+
+// Less return a less function over the elements of src for sort.Slice or
+// SortParallel. It fails if an element lacks one of the criteria or if
+// its values can't be ordered.
+func (k *Key) Less(src interface{}) (func(i, j int) bool, error) {
+	sv, err := sortable(src)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := k.values(sv); err != nil {
+		return nil, err
+	}
+	return func(i, j int) bool {
+		a, _ := k.extract(sv.Index(i))
+		b, _ := k.extract(sv.Index(j))
+		return k.compare(a, b) < 0
+	}, nil
+}
+
+// SortBy sort src in place by key. The sort is stable and each criterion
+// is evaluated once per element, so methods doing some work are fine.
+func SortBy(src interface{}, key *Key) error {
+	sv, err := sortable(src)
+	if err != nil {
+		return err
+	}
+
+	vals, err := key.values(sv)
+	if err != nil {
+		return err
+	}
+
+	perm := make([]int, sv.Len())
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool {
+		return key.compare(vals[perm[a]], vals[perm[b]]) < 0
+	})
+
+	tmp := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+	for i, p := range perm {
+		tmp.Index(i).Set(sv.Index(p))
+	}
+	reflect.Copy(sv, tmp)
+	return nil
+}
+
+// values extract the criteria of every element of sv
+func (k *Key) values(sv reflect.Value) ([][]reflect.Value, error) {
+	vals := make([][]reflect.Value, sv.Len())
+	for i := range vals {
+		v, err := k.extract(sv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("src[%d]: %w", i, err)
+		}
+		vals[i] = v
+	}
+	// check the values can be ordered once, instead of at every comparison
+	if len(vals) > 1 {
+		for n := range k.names {
+			for i := 1; i < len(vals); i++ {
+				if _, err := compareKey(vals[0][n], vals[i][n]); err != nil {
+					return nil, fmt.Errorf("%s: %w", k.names[n], err)
+				}
+			}
+		}
+	}
+	return vals, nil
+}
+
+// extract the criteria of v
+func (k *Key) extract(v reflect.Value) ([]reflect.Value, error) {
+	out := make([]reflect.Value, len(k.names))
+	for i, name := range k.names {
+		f, err := lookup(v, name)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+func (k *Key) compare(a, b []reflect.Value) int {
+	for i := range a {
+		c, _ := compareKey(a[i], b[i])
+		if k.desc[i] {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// lookup the method without arguments or the field name of v
+func lookup(v reflect.Value, name string) (reflect.Value, error) {
+	for {
+		if m := v.MethodByName(name); m.IsValid() {
+			if m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+				return reflect.Value{}, fmt.Errorf("%s is not a method without arguments returning one value", name)
+			}
+			return m.Call(nil)[0], nil
+		}
+		if v.CanAddr() {
+			if m := v.Addr().MethodByName(name); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+				return m.Call(nil)[0], nil
+			}
+		}
+		if (v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface) || v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName(name); f.IsValid() {
+			return f, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%s has no field or method %s", typeOf(v), name)
+}
+
+// compareKey compare two criteria values, see Key
+func compareKey(a, b reflect.Value) (int, error) {
+	if a.Type() != b.Type() {
+		return 0, notSameType("b", b.Type(), a.Type())
+	}
+	if a.Kind() == reflect.Bool {
+		return cmp(!a.Bool() && b.Bool(), a.Bool() && !b.Bool()), nil
+	}
+	if m := a.MethodByName("Before"); m.IsValid() && m.Type().NumIn() == 1 && m.Type().In(0) == b.Type() &&
+		m.Type().NumOut() == 1 && m.Type().Out(0).Kind() == reflect.Bool {
+		return cmp(m.Call([]reflect.Value{b})[0].Bool(), b.MethodByName("Before").Call([]reflect.Value{a})[0].Bool()), nil
+	}
+	return compareValues(a, b)
+}
+
+// sortable the slice src, or the slice or array src points to
+func sortable(src interface{}) (reflect.Value, error) {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		sv = sv.Elem()
+	}
+	if sv.Kind() == reflect.Array && sv.CanAddr() {
+		sv = sv.Slice(0, sv.Len())
+	}
+	if !isSlice(sv) {
+		return sv, notSlice("src", sv)
+	}
+	return sv, nil
+}
@@ -0,0 +1,72 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type sortByFile struct {
+	Name string
+	Size int64
+	Dir  bool
+	Mod  time.Time
+}
+
+func (f sortByFile) Ext() string { return f.Name[len(f.Name)-1:] }
+
+func TestSortBy(t *testing.T) {
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := sortByFile{"a.c", 10, false, t0}
+	b := sortByFile{"b.c", 30, false, t0.Add(time.Hour)}
+	c := sortByFile{"c.h", 20, true, t0}
+	d := sortByFile{"a.c", 20, false, t0.Add(-time.Hour)}
+
+	tests := []struct {
+		name    string
+		key     *Key
+		correct []sortByFile
+	}{
+		{"By", By("Name"), []sortByFile{a, d, b, c}},
+		{"ThenByDesc", By("Name").ThenByDesc("Size"), []sortByFile{d, a, b, c}},
+		{"ByDesc", ByDesc("Size"), []sortByFile{b, c, d, a}},
+		{"method", By("Ext").ThenBy("Size"), []sortByFile{a, d, b, c}},
+		{"bool", ByDesc("Dir").ThenBy("Name"), []sortByFile{c, a, d, b}},
+		{"Before", By("Mod").ThenBy("Size"), []sortByFile{d, a, c, b}},
+	}
+	for _, tc := range tests {
+		src := []sortByFile{a, b, c, d}
+		if err := SortBy(src, tc.key); !reflect.DeepEqual(src, tc.correct) || err != nil {
+			t.Errorf("[slice]: SortBy %s test failed, expecting %v, got %v, err %v", tc.name, tc.correct, src, err)
+		}
+
+		src = []sortByFile{a, b, c, d}
+		less, err := tc.key.Less(src)
+		if err != nil {
+			t.Errorf("[slice]: Key.Less %s test failed with %v", tc.name, err)
+			continue
+		}
+		sort.SliceStable(src, less)
+		if !reflect.DeepEqual(src, tc.correct) {
+			t.Errorf("[slice]: Key.Less %s test failed, expecting %v, got %v", tc.name, tc.correct, src)
+		}
+	}
+
+	ptrs := []*sortByFile{&b, &a}
+	if err := SortBy(ptrs, By("Size")); err != nil || ptrs[0] != &a {
+		t.Errorf("[slice]: SortBy pointers test failed, expecting %v first, got %v, err %v", a, ptrs[0], err)
+	}
+
+	src := []sortByFile{b, a}
+	if err := SortBy(src, By("Missing")); err == nil || !reflect.DeepEqual(src, []sortByFile{b, a}) {
+		t.Errorf("[slice]: SortBy test failed, expecting an error and src untouched, got %v, err %v", src, err)
+	}
+	if _, err := By("Name").Less([]int{1}); err == nil {
+		t.Errorf("[slice]: Key.Less test failed, expecting an error for non-struct elements")
+	}
+	if err := SortBy(1, By("Name")); !errors.Is(err, ErrNotSlice) {
+		t.Errorf("[slice]: SortBy test failed, expecting %v, got %v", ErrNotSlice, err)
+	}
+}
@@ -0,0 +1,80 @@
+package slice
+
+import (
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Stats the summary of a numeric slice returned by Describe
+type Stats struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+// Describe summarize a slice of any integer or float kind, time.Duration
+// included. The percentiles are linearly interpolated between the closest ranks.
+func Describe(src interface{}) (Stats, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return Stats{}, notSlice("src", sv)
+	}
+	if !isNumeric(sv.Type().Elem().Kind()) {
+		return Stats{}, ErrNotNumeric
+	}
+	if sv.Len() == 0 {
+		return Stats{}, ErrEmpty
+	}
+
+	vals := make([]float64, sv.Len())
+	var sum float64
+	for i := range vals {
+		vals[i] = toFloat(sv.Index(i))
+		sum += vals[i]
+	}
+	sort.Float64s(vals)
+
+	return Stats{
+		Count: len(vals),
+		Min:   vals[0],
+		Max:   vals[len(vals)-1],
+		Mean:  sum / float64(len(vals)),
+		P50:   percentile(vals, 50),
+		P90:   percentile(vals, 90),
+		P99:   percentile(vals, 99),
+	}, nil
+}
+
+// percentile the p-th percentile of the sorted vals
+func percentile(vals []float64, p float64) float64 {
+	rank := p / 100 * float64(len(vals)-1)
+	lo := math.Floor(rank)
+	hi := math.Ceil(rank)
+	return vals[int(lo)] + (vals[int(hi)]-vals[int(lo)])*(rank-lo)
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toFloat convert a value of numeric kind to float64
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
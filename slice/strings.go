@@ -0,0 +1,58 @@
+package slice
+
+import "strings"
+
+// TrimSpaceAll return a new slice of the elements of src without their leading and trailing white space
+func TrimSpaceAll(src []string) []string {
+	return mapStrings(src, strings.TrimSpace)
+}
+
+// ToLowerAll return a new slice of the elements of src in lower case
+func ToLowerAll(src []string) []string {
+	return mapStrings(src, strings.ToLower)
+}
+
+// ToUpperAll return a new slice of the elements of src in upper case
+func ToUpperAll(src []string) []string {
+	return mapStrings(src, strings.ToUpper)
+}
+
+// RemoveEmpty return a new slice of the non-empty elements of src,
+// combine with TrimSpaceAll to drop the blank ones too
+func RemoveEmpty(src []string) []string {
+	tmp := make([]string, 0, len(src))
+	for _, v := range src {
+		if len(v) > 0 {
+			tmp = append(tmp, v)
+		}
+	}
+	return tmp
+}
+
+// HasPrefixAny whether any element of src begins with prefix
+func HasPrefixAny(src []string, prefix string) bool {
+	for _, v := range src {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSuffixAny whether any element of src ends with suffix
+func HasSuffixAny(src []string, suffix string) bool {
+	for _, v := range src {
+		if strings.HasSuffix(v, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func mapStrings(src []string, fn func(string) string) []string {
+	tmp := make([]string, len(src))
+	for i, v := range src {
+		tmp[i] = fn(v)
+	}
+	return tmp
+}
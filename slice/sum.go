@@ -0,0 +1,124 @@
+package slice
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// SumChecked sum a slice of any integer or float kind into a value of its
+// element type, eg: int64 for []int64 and time.Duration for []time.Duration.
+// It returns ErrOverflow instead of silently wrapping around when the total
+// doesn't fit the element type.
+func SumChecked(src interface{}) (interface{}, error) {
+	sv, err := numericSlice(src)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := reflect.New(sv.Type().Elem()).Elem()
+	for i := 0; i < sv.Len(); i++ {
+		v := sv.Index(i)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s, x := sum.Int(), v.Int()
+			r := s + x
+			if (x > 0 && r < s) || (x < 0 && r > s) || sum.OverflowInt(r) {
+				return nil, overflow(i)
+			}
+			sum.SetInt(r)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			s := sum.Uint()
+			r := s + v.Uint()
+			if r < s || sum.OverflowUint(r) {
+				return nil, overflow(i)
+			}
+			sum.SetUint(r)
+		default:
+			s, x := sum.Float(), v.Float()
+			r := s + x
+			if (math.IsInf(r, 0) && !math.IsInf(s, 0) && !math.IsInf(x, 0)) || sum.OverflowFloat(r) {
+				return nil, overflow(i)
+			}
+			sum.SetFloat(r)
+		}
+	}
+	return sum.Interface(), nil
+}
+
+// SumBig sum a slice of any integer kind with arbitrary precision
+func SumBig(src interface{}) (*big.Int, error) {
+	sv, err := numericSlice(src)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := new(big.Int)
+	x := new(big.Int)
+	for i := 0; i < sv.Len(); i++ {
+		v := sv.Index(i)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x.SetInt64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			x.SetUint64(v.Uint())
+		default:
+			return nil, ErrNotNumeric
+		}
+		sum.Add(sum, x)
+	}
+	return sum, nil
+}
+
+// SumBigFloat sum a slice of any integer or float kind with arbitrary
+// precision: the total is exact, unlike adding float64s which rounds at
+// every step.
+func SumBigFloat(src interface{}) (*big.Float, error) {
+	sv, err := numericSlice(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if k := sv.Type().Elem().Kind(); k != reflect.Float32 && k != reflect.Float64 {
+		sum, err := SumBig(src)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Float).SetInt(sum), nil
+	}
+
+	// enough bits to hold any sum of float64s exactly
+	sum := new(big.Float).SetPrec(exactFloatPrec)
+	x := new(big.Float)
+	for i := 0; i < sv.Len(); i++ {
+		f := sv.Index(i).Float()
+		if math.IsNaN(f) {
+			return nil, fmt.Errorf("src[%d]: %w", i, ErrNotNumeric)
+		}
+		if math.IsInf(f, 0) {
+			return nil, overflow(i)
+		}
+		x.SetFloat64(f)
+		sum.Add(sum, x)
+	}
+	return sum, nil
+}
+
+// exactFloatPrec the exponent range of float64 plus its mantissa and room for carries
+const exactFloatPrec = 2098 + 64
+
+func numericSlice(src interface{}) (reflect.Value, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return sv, notSlice("src", sv)
+	}
+	if !isNumeric(sv.Type().Elem().Kind()) {
+		return sv, ErrNotNumeric
+	}
+	return sv, nil
+}
+
+func overflow(i int) error {
+	return fmt.Errorf("src[%d]: %w", i, ErrOverflow)
+}
@@ -0,0 +1,56 @@
+package slice
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSumChecked(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		correct interface{}
+		err     error
+	}{
+		{[]int{1, 2, 3}, 6, nil},
+		{[]time.Duration{time.Second, time.Minute}, 61 * time.Second, nil},
+		{[]int8{100, 27}, int8(127), nil},
+		{[]int8{100, 28}, nil, ErrOverflow},
+		{[]int64{math.MinInt64, -1}, nil, ErrOverflow},
+		{[]uint8{200, 56}, nil, ErrOverflow},
+		{[]float64{0.5, 0.25}, 0.75, nil},
+		{[]float64{math.MaxFloat64, math.MaxFloat64}, nil, ErrOverflow},
+		{[]int{}, 0, nil},
+		{[]string{"a"}, nil, ErrNotNumeric},
+	}
+	for _, tc := range tests {
+		res, err := SumChecked(tc.src)
+		if !reflect.DeepEqual(res, tc.correct) || !errors.Is(err, tc.err) {
+			t.Errorf("[slice]: SumChecked test failed for %v, expecting %v, got %v, err %v", tc.src, tc.correct, res, err)
+		}
+	}
+}
+
+func TestSumBig(t *testing.T) {
+	huge, _ := new(big.Int).SetString("36893488147419103230", 10)
+	tests := []struct {
+		src     interface{}
+		correct *big.Int
+	}{
+		{[]int{1, -2, 3}, big.NewInt(2)},
+		{[]uint64{math.MaxUint64, math.MaxUint64}, huge},
+		{[]int8{}, big.NewInt(0)},
+	}
+	for _, tc := range tests {
+		res, err := SumBig(tc.src)
+		if err != nil || res.Cmp(tc.correct) != 0 {
+			t.Errorf("[slice]: SumBig test failed for %v, expecting %s, got %s, err %v", tc.src, tc.correct, res, err)
+		}
+	}
+	if _, err := SumBig([]float64{1}); err == nil {
+		t.Errorf("[slice]: SumBig test failed, expecting an error for floats")
+	}
+}
@@ -0,0 +1,115 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Take return a new slice of the first n elements of src.
+// n is clamped to the length of src.
+func Take(src interface{}, n int) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+	return subSlice(sv, 0, clamp(n, sv.Len())), nil
+}
+
+// Drop return a new slice without the first n elements of src.
+// n is clamped to the length of src.
+func Drop(src interface{}, n int) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+	return subSlice(sv, clamp(n, sv.Len()), sv.Len()), nil
+}
+
+// TakeWhile return a new slice of the leading elements of src satisfying pred
+func TakeWhile(src interface{}, pred func(interface{}) bool) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+	return subSlice(sv, 0, prefixLen(sv, pred)), nil
+}
+
+// DropWhile return a new slice without the leading elements of src satisfying pred
+func DropWhile(src interface{}, pred func(interface{}) bool) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+	return subSlice(sv, prefixLen(sv, pred), sv.Len()), nil
+}
+
+// First return the first element of src, or fallback if src is empty
+func First(src interface{}, fallback interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return fallback, notSlice("src", sv)
+	}
+	if sv.Len() == 0 {
+		return fallback, nil
+	}
+	return sv.Index(0).Interface(), nil
+}
+
+// Last return the last element of src, or fallback if src is empty
+func Last(src interface{}, fallback interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return fallback, notSlice("src", sv)
+	}
+	if sv.Len() == 0 {
+		return fallback, nil
+	}
+	return sv.Index(sv.Len() - 1).Interface(), nil
+}
+
+// At return the element of src at index i, counting from the end if i is
+// negative like Python does, eg: -1 is the last element and -2 the second
+// from the end. An index outside of src is ErrOutOfRange instead of a panic.
+func At(src interface{}, i int) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+	j := i
+	if j < 0 {
+		j += sv.Len()
+	}
+	if j < 0 || j >= sv.Len() {
+		return nil, fmt.Errorf("index %d of %d elements: %w", i, sv.Len(), ErrOutOfRange)
+	}
+	return sv.Index(j).Interface(), nil
+}
+
+// prefixLen the number of leading elements satisfying pred
+func prefixLen(v reflect.Value, pred func(interface{}) bool) int {
+	i := 0
+	for ; i < v.Len(); i++ {
+		if !pred(v.Index(i).Interface()) {
+			break
+		}
+	}
+	return i
+}
+
+// subSlice copy v[i:j] to a new slice, so that it does not share the backing array
+func subSlice(v reflect.Value, i, j int) interface{} {
+	tmp := reflect.MakeSlice(v.Type(), j-i, j-i)
+	reflect.Copy(tmp, v.Slice(i, j))
+	return tmp.Interface()
+}
+
+// clamp n into [0, max]
+func clamp(n, max int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,31 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		i       int
+		correct interface{}
+		err     error
+	}{
+		{[]int{1, 2, 3}, 0, 1, nil},
+		{[]int{1, 2, 3}, 2, 3, nil},
+		{[]int{1, 2, 3}, -1, 3, nil},
+		{[3]string{"a", "b", "c"}, -3, "a", nil},
+		{[]int{1, 2, 3}, 3, nil, ErrOutOfRange},
+		{[]int{1, 2, 3}, -4, nil, ErrOutOfRange},
+		{[]int{}, 0, nil, ErrOutOfRange},
+		{"abc", 0, nil, ErrNotSlice},
+	}
+	for _, tc := range tests {
+		res, err := At(tc.src, tc.i)
+		if !reflect.DeepEqual(res, tc.correct) || !errors.Is(err, tc.err) {
+			t.Errorf("[slice]: At test failed for index %d of %v, expecting %v, got %v, err %v", tc.i, tc.src, tc.correct, res, err)
+		}
+	}
+}
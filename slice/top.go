@@ -0,0 +1,90 @@
+package slice
+
+import (
+	"container/heap"
+	"reflect"
+	"sort"
+)
+
+// MaxN return a new slice of the n largest elements of src, largest first,
+// eg: the n most recent files. Elements are compared by the optional less,
+// or by their natural order if they are of an ordered kind. It keeps a heap
+// of n elements, which is O(len(src) log n) instead of sorting everything.
+func MaxN(src interface{}, n int, less ...func(a, b interface{}) bool) (interface{}, error) {
+	return topN(src, n, true, less...)
+}
+
+// MinN return a new slice of the n smallest elements of src, smallest first, see MaxN
+func MinN(src interface{}, n int, less ...func(a, b interface{}) bool) (interface{}, error) {
+	return topN(src, n, false, less...)
+}
+
+func topN(src interface{}, n int, max bool, less ...func(a, b interface{}) bool) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return nil, notSlice("src", sv)
+	}
+
+	n = clamp(n, sv.Len())
+
+	var err error
+	lessFn := func(i, j int) bool {
+		if len(less) > 0 {
+			return less[0](sv.Index(i).Interface(), sv.Index(j).Interface())
+		}
+		c, e := compareValues(sv.Index(i), sv.Index(j))
+		if e != nil && err == nil {
+			err = e
+		}
+		return c < 0
+	}
+	// better(i, j) whether element i belongs in the result before element j
+	better := func(i, j int) bool {
+		if max {
+			return lessFn(j, i)
+		}
+		return lessFn(i, j)
+	}
+
+	// the root of the heap is the worst element kept so far
+	h := &indexHeap{worse: func(i, j int) bool { return better(j, i) }}
+	for i := 0; i < sv.Len() && n > 0; i++ {
+		if h.Len() < n {
+			heap.Push(h, i)
+		} else if better(i, h.idx[0]) {
+			h.idx[0] = i
+			heap.Fix(h, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(h.idx, func(a, b int) bool { return better(h.idx[a], h.idx[b]) })
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := reflect.MakeSlice(reflect.SliceOf(sv.Type().Elem()), len(h.idx), len(h.idx))
+	for i, j := range h.idx {
+		tmp.Index(i).Set(sv.Index(j))
+	}
+
+	return tmp.Interface(), nil
+}
+
+// indexHeap a heap.Interface of indexes into a slice
+type indexHeap struct {
+	idx   []int
+	worse func(i, j int) bool
+}
+
+func (h *indexHeap) Len() int           { return len(h.idx) }
+func (h *indexHeap) Less(i, j int) bool { return h.worse(h.idx[i], h.idx[j]) }
+func (h *indexHeap) Swap(i, j int)      { h.idx[i], h.idx[j] = h.idx[j], h.idx[i] }
+func (h *indexHeap) Push(x interface{}) { h.idx = append(h.idx, x.(int)) }
+func (h *indexHeap) Pop() interface{} {
+	x := h.idx[len(h.idx)-1]
+	h.idx = h.idx[:len(h.idx)-1]
+	return x
+}
@@ -0,0 +1,35 @@
+package slice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaxNMinN(t *testing.T) {
+	byLen := func(a, b interface{}) bool { return len(a.(string)) < len(b.(string)) }
+	tests := []struct {
+		name    string
+		fn      func(interface{}, int, ...func(a, b interface{}) bool) (interface{}, error)
+		src     interface{}
+		n       int
+		less    []func(a, b interface{}) bool
+		correct interface{}
+	}{
+		{"MaxN", MaxN, []int{3, 1, 4, 1, 5, 9, 2, 6}, 3, nil, []int{9, 6, 5}},
+		{"MinN", MinN, []int{3, 1, 4, 1, 5, 9, 2, 6}, 3, nil, []int{1, 1, 2}},
+		{"MaxN more than len", MaxN, []float64{1.5, -2}, 5, nil, []float64{1.5, -2}},
+		{"MinN zero", MinN, []string{"b", "a"}, 0, nil, []string{}},
+		{"MaxN less", MaxN, []string{"ccc", "a", "bb"}, 2, []func(a, b interface{}) bool{byLen}, []string{"ccc", "bb"}},
+		{"MinN array", MinN, [3]string{"c", "a", "b"}, 2, nil, []string{"a", "b"}},
+	}
+	for _, tc := range tests {
+		res, err := tc.fn(tc.src, tc.n, tc.less...)
+		if !reflect.DeepEqual(res, tc.correct) || err != nil {
+			t.Errorf("[slice]: %s test failed, expecting %v, got %v, err %v", tc.name, tc.correct, res, err)
+		}
+	}
+
+	if _, err := MaxN([]struct{}{{}, {}}, 1); err == nil {
+		t.Errorf("[slice]: MaxN test failed, expecting an error for unordered elements")
+	}
+}
@@ -0,0 +1,81 @@
+package slice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ElementError the element at Index of a slice failed validation with Err
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	// a nested validation error already names its elements, eg: "[1][0]: missing name"
+	if nested, ok := e.Err.(ValidationError); ok {
+		s := make([]string, len(nested))
+		for i, v := range nested {
+			s[i] = fmt.Sprintf("[%d]%s", e.Index, v)
+		}
+		return strings.Join(s, "; ")
+	}
+	return fmt.Sprintf("[%d]: %s", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError the errors of all the elements that failed validation, in order
+type ValidationError []*ElementError
+
+func (e ValidationError) Error() string {
+	s := make([]string, len(e))
+	for i, v := range e {
+		s[i] = v.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Indices the indexes of the elements that failed validation
+func (e ValidationError) Indices() []int {
+	idx := make([]int, len(e))
+	for i, v := range e {
+		idx[i] = v.Index
+	}
+	return idx
+}
+
+// Validate call fn with every element of src and its index, and return a
+// ValidationError gathering the errors fn returned, nil if there was none,
+// eg: "[0]: missing name; [3]: invalid port 0". fn can itself call Validate
+// on a nested slice, its failing elements are then named like "[1][0]".
+func Validate(src interface{}, fn func(i int, v interface{}) error) error {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) && sv.Kind() != reflect.Array {
+		return notSlice("src", sv)
+	}
+
+	var errs ValidationError
+	for i := 0; i < sv.Len(); i++ {
+		if err := fn(i, sv.Index(i).Interface()); err != nil {
+			errs = append(errs, &ElementError{Index: i, Err: err})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Apply call fn with every element of src, going on after failures, and
+// return a ValidationError naming the indexes of the elements fn failed on,
+// nil if it never did.
+func Apply(src interface{}, fn func(v interface{}) error) error {
+	return Validate(src, func(_ int, v interface{}) error {
+		return fn(v)
+	})
+}
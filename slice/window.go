@@ -0,0 +1,109 @@
+package slice
+
+import "reflect"
+
+// Windows return the windows of size elements of src, starting every step
+// elements, eg: Windows([]int{1, 2, 3, 4}, 2, 1) returns [][]int{{1, 2}, {2, 3}, {3, 4}}.
+// Only full windows are returned. They share the backing array of src, so
+// nothing is copied, but modifying a window modifies src.
+func Windows(src interface{}, size, step int) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	n := 0
+	if sv.Len() >= size && size > 0 && step > 0 {
+		n = (sv.Len()-size)/step + 1
+	}
+	tmp := reflect.MakeSlice(reflect.SliceOf(sv.Type()), 0, n)
+
+	err := WindowsEach(src, size, step, func(w interface{}) bool {
+		tmp = reflect.Append(tmp, reflect.ValueOf(w))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tmp.Interface(), nil
+}
+
+// WindowsEach call fn with every window of Windows without building the list of
+// windows. Returning false from fn stops the iteration.
+func WindowsEach(src interface{}, size, step int, fn func(window interface{}) bool) error {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return notSlice("src", sv)
+	}
+
+	if size < 1 || step < 1 {
+		return ErrOutOfRange
+	}
+
+	for i := 0; i+size <= sv.Len(); i += step {
+		// full slice expression, so that appending to a window does not overwrite src
+		if !fn(sv.Slice3(i, i+size, i+size).Interface()) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// SplitN divide src into n parts of balanced sizes, differing by at most one,
+// the longer parts first, eg: SplitN([]int{1, 2, 3, 4, 5}, 3) returns
+// [][]int{{1, 2}, {3, 4}, {5}}. There are always n parts, some are empty if
+// src has less than n elements, so that they can be handed to n workers.
+// Like windows, the parts share the backing array of src.
+func SplitN(src interface{}, n int) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	if n < 1 {
+		return nil, ErrOutOfRange
+	}
+
+	size, rest := sv.Len()/n, sv.Len()%n
+	tmp := reflect.MakeSlice(reflect.SliceOf(sv.Type()), n, n)
+	for i, start := 0, 0; i < n; i++ {
+		end := start + size
+		if i < rest {
+			end++
+		}
+		tmp.Index(i).Set(sv.Slice3(start, end, end))
+		start = end
+	}
+
+	return tmp.Interface(), nil
+}
+
+// GroupConsecutive split src into the runs of adjacent elements keyFn returns
+// the same key for, in order, like Python's itertools.groupby, eg: grouping
+// []int{1, 1, 2, 1} by identity returns [][]int{{1, 1}, {2}, {1}}. Keys are
+// compared deeply. Like windows, the runs share the backing array of src.
+func GroupConsecutive(src interface{}, keyFn func(interface{}) interface{}) (interface{}, error) {
+	sv := reflect.ValueOf(src)
+	if !isSlice(sv) {
+		return nil, notSlice("src", sv)
+	}
+
+	tmp := reflect.MakeSlice(reflect.SliceOf(sv.Type()), 0, 0)
+	var last interface{}
+	start := 0
+	for i := 0; i < sv.Len(); i++ {
+		k := genKey(reflect.ValueOf(keyFn(sv.Index(i).Interface())))
+		if i > 0 && k != last {
+			tmp = reflect.Append(tmp, sv.Slice3(start, i, i))
+			start = i
+		}
+		last = k
+	}
+	if sv.Len() > 0 {
+		tmp = reflect.Append(tmp, sv.Slice3(start, sv.Len(), sv.Len()))
+	}
+
+	return tmp.Interface(), nil
+}
@@ -0,0 +1,42 @@
+package slice
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupConsecutive(t *testing.T) {
+	identity := func(v interface{}) interface{} { return v }
+	tests := []struct {
+		src     interface{}
+		keyFn   func(interface{}) interface{}
+		correct interface{}
+	}{
+		{[]int{1, 1, 2, 1}, identity, [][]int{{1, 1}, {2}, {1}}},
+		{[]int{}, identity, [][]int{}},
+		{[]string{"ab", "ac", "b", "AD"}, func(v interface{}) interface{} {
+			return strings.ToLower(v.(string)[:1])
+		}, [][]string{{"ab", "ac"}, {"b"}, {"AD"}}},
+		{[]int{1, 2, 3}, func(v interface{}) interface{} { return []int{v.(int) / 2} }, [][]int{{1}, {2, 3}}},
+	}
+	for _, tc := range tests {
+		res, err := GroupConsecutive(tc.src, tc.keyFn)
+		if !reflect.DeepEqual(res, tc.correct) || err != nil {
+			t.Errorf("[slice]: GroupConsecutive test failed, expecting %v, got %v, err %v", tc.correct, res, err)
+		}
+	}
+
+	// the runs are capped, appending to one must not clobber the next
+	src := []int{1, 2}
+	res, _ := GroupConsecutive(src, identity)
+	_ = append(res.([][]int)[0], 9)
+	if src[1] != 2 {
+		t.Errorf("[slice]: GroupConsecutive test failed, appending to a run changed src to %v", src)
+	}
+
+	if _, err := GroupConsecutive([1]int{1}, identity); !errors.Is(err, ErrNotSlice) {
+		t.Errorf("[slice]: GroupConsecutive test failed, expecting %v, got %v", ErrNotSlice, err)
+	}
+}